@@ -0,0 +1,544 @@
+package go_routeros
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// cliCommands words recognised as the command at the end of a menu path
+var cliCommands = map[string]bool{
+	"add": true, "set": true, "remove": true, "print": true, "enable": true,
+	"disable": true, "unset": true, "get": true, "getall": true, "listen": true,
+	"comment": true, "move": true, "reset": true, "reset-counters": true,
+	"monitor": true, "export": true, "run": true, "find": true,
+}
+
+// CommandLine is a CLI command parsed into API words
+type CommandLine struct {
+	// Path the API command, e.g. /ip/address/add
+	Path string
+	// Args the attribute words, e.g. =address=10.0.0.1/24
+	Args []string
+	// Query the [find ...] or where clause
+	Query Query
+
+	// find the line has a [find ...] or where clause, which may be empty
+	find bool
+}
+
+// Menu returns the menu of the command, e.g. /ip/address
+func (c *CommandLine) Menu() string {
+	return c.Path[:strings.LastIndex(c.Path, "/")]
+}
+
+// Command returns the command word, e.g. add
+func (c *CommandLine) Command() string {
+	return c.Path[strings.LastIndex(c.Path, "/")+1:]
+}
+
+// Words returns the attribute words followed by the query words
+func (c *CommandLine) Words() []string {
+	return append(append([]string(nil), c.Args...), c.Query.Words()...)
+}
+
+// String renders the command back as CLI text
+func (c *CommandLine) String() string {
+	return NormalizeToCommandLine(c.Path, c.Words()...)
+}
+
+// ParseCommandLine parses a CLI command such as
+//
+//	/ip address add address=10.0.0.1/24 interface=ether1
+//	/ip firewall filter set [find comment="x"] disabled=yes
+//	/interface print where type=ether and !disabled
+//
+// Values may be quoted with the CLI escapes. A positional item reference
+// becomes =.id= when it starts with * and =numbers= otherwise, the second
+// positional argument of unset becomes =value-name=. The path ends at the
+// usual commands such as add or print, other commands end it at the first
+// argument, [find ...] or where, e.g. /system reboot. A positional argument
+// of such a command needs the path joined with /, e.g.
+//
+//	/interface/monitor-traffic *1 once=yes
+func ParseCommandLine(line string) (*CommandLine, error) {
+	tokens, err := splitCommandLine(line)
+	if err != nil {
+		return nil, err
+	}
+
+	var path []string
+	i, known := 0, false
+	for ; i < len(tokens) && !known; i++ {
+		t := tokens[i]
+		if t.block || t.where || t.quoted || t.hasValue || !isMenuPath(t.key) {
+			break
+		}
+		path = append(path, pathSegments(t.key)...)
+		known = cliCommands[path[len(path)-1]]
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("missing command in %q", line)
+	}
+	if t := tokens[min(i, len(tokens)-1)]; !known && i > 1 && i < len(tokens) && !t.block && !t.where && !t.hasValue {
+		// a positional argument of an unknown command, only a path joined by
+		// / tells where the path ends
+		if !strings.Contains(strings.Trim(tokens[0].key, "/"), "/") {
+			return nil, fmt.Errorf("unknown command in %q, join its path with / such as /interface/monitor-traffic", line)
+		}
+		path, i = pathSegments(tokens[0].key), 1
+	}
+	cmd := &CommandLine{Path: "/" + strings.Join(path, "/")}
+	command := path[len(path)-1]
+
+	positional := 0
+	var queries []Query
+	for ; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t.block:
+			q, err := parseFind(t.key)
+			if err != nil {
+				return nil, err
+			}
+			queries = append(queries, q)
+			cmd.find = true
+		case t.where:
+			q, err := parseExpression(t.key)
+			if err != nil {
+				return nil, err
+			}
+			queries = append(queries, q)
+			cmd.find = true
+		case t.hasValue:
+			cmd.Args = append(cmd.Args, "="+t.key+"="+t.value)
+		case command == "print":
+			// flags such as detail or count-only
+			cmd.Args = append(cmd.Args, "="+t.key+"=")
+		default:
+			positional++
+			switch {
+			case positional == 2 && command == "unset":
+				cmd.Args = append(cmd.Args, "=value-name="+t.key)
+			case positional > 1 || command == "add":
+				return nil, fmt.Errorf("unexpected argument %q", t.key)
+			case strings.HasPrefix(t.key, "*"):
+				cmd.Args = append(cmd.Args, "=.id="+t.key)
+			default:
+				cmd.Args = append(cmd.Args, "=numbers="+t.key)
+			}
+		}
+	}
+	cmd.Query = And(queries...)
+	return cmd, nil
+}
+
+// RunCommandLine parses a CLI command and runs it. A [find ...] on a command
+// other than print is resolved first with a print of the matching .id values,
+// the command is not sent when nothing matches. An empty [find] matches every
+// item.
+func (c *Client) RunCommandLine(ctx context.Context, line string) (*Reply, error) {
+	cmd, err := ParseCommandLine(line)
+	if err != nil {
+		return nil, err
+	}
+	if !cmd.find || cmd.Command() == "print" {
+		return c.Run(ctx, cmd.Path, cmd.Words()...)
+	}
+
+	args := append([]string{"=.proplist=.id"}, cmd.Query.Words()...)
+	found, err := c.Run(ctx, cmd.Menu()+"/print", args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(found.Re) == 0 {
+		return &Reply{}, nil
+	}
+	ids := make([]string, len(found.Re))
+	for i, s := range found.Re {
+		ids[i] = s.Value(".id")
+	}
+	return c.Run(ctx, cmd.Path, append(cmd.Args, "=.id="+strings.Join(ids, ","))...)
+}
+
+// pathSegments splits a menu path such as /ip/address or address
+func pathSegments(key string) []string {
+	var segments []string
+	for _, segment := range strings.Split(key, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// isMenuPath reports whether key may be part of a menu path, unlike values
+// such as 8.8.8.8 or *1
+func isMenuPath(key string) bool {
+	segments := pathSegments(key)
+	if len(segments) == 0 {
+		return false
+	}
+	for _, segment := range segments {
+		if segment[0] < 'a' || segment[0] > 'z' {
+			return false
+		}
+		for i := 0; i < len(segment); i++ {
+			if c := segment[i]; !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type cliToken struct {
+	key      string
+	value    string
+	hasValue bool
+	quoted   bool
+	// block the text inside [ ]
+	block bool
+	// where the rest of the line after the where keyword
+	where bool
+}
+
+// splitCommandLine splits the line into words, key=value pairs, [ ] blocks
+// and a trailing where clause
+func splitCommandLine(line string) ([]cliToken, error) {
+	var tokens []cliToken
+	i := 0
+	for {
+		for i < len(line) && isSpace(line[i]) {
+			i++
+		}
+		if i >= len(line) {
+			return tokens, nil
+		}
+		if line[i] == '[' {
+			end, err := matchBracket(line, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, cliToken{key: line[i+1 : end], block: true})
+			i = end + 1
+			continue
+		}
+
+		t := cliToken{}
+		b := strings.Builder{}
+		for i < len(line) && !isSpace(line[i]) && line[i] != '[' {
+			switch {
+			case line[i] == '"':
+				s, n, err := unquote(line[i:])
+				if err != nil {
+					return nil, err
+				}
+				b.WriteString(s)
+				t.quoted = true
+				i += n
+			case line[i] == '=' && !t.hasValue:
+				t.key, t.hasValue = b.String(), true
+				b.Reset()
+				i++
+			default:
+				b.WriteByte(line[i])
+				i++
+			}
+		}
+		if t.hasValue {
+			t.value = b.String()
+			t.quoted = false
+		} else {
+			t.key = b.String()
+		}
+		if t.key == "where" && !t.hasValue && !t.quoted {
+			tokens = append(tokens, cliToken{key: line[i:], where: true})
+			return tokens, nil
+		}
+		tokens = append(tokens, t)
+	}
+}
+
+// matchBracket returns the index of the ] closing the [ at start
+func matchBracket(line string, start int) (int, error) {
+	depth := 0
+	for i := start; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			_, n, err := unquote(line[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n - 1
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unterminated [ in %q", line)
+}
+
+// unquote reads a quoted string at the start of s and returns its value and
+// the number of bytes consumed
+func unquote(s string) (string, int, error) {
+	b := strings.Builder{}
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return b.String(), i + 1, nil
+		case '$':
+			return "", 0, fmt.Errorf("variables are not supported in %q", s)
+		case '\\':
+			i++
+			if i >= len(s) {
+				return "", 0, fmt.Errorf("unterminated escape in %q", s)
+			}
+			switch c := s[i]; c {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'a':
+				b.WriteByte('\a')
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'v':
+				b.WriteByte('\v')
+			case '_':
+				b.WriteByte(' ')
+			default:
+				if isHex(c) && i+1 < len(s) && isHex(s[i+1]) {
+					v, _ := strconv.ParseUint(s[i:i+2], 16, 8)
+					b.WriteByte(byte(v))
+					i++
+				} else {
+					b.WriteByte(c)
+				}
+			}
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quote in %q", s)
+}
+
+// parseFind parses the inside of [find ...]
+func parseFind(s string) (Query, error) {
+	s = strings.TrimSpace(s)
+	if s != "find" && !strings.HasPrefix(s, "find ") {
+		return Query{}, fmt.Errorf("unsupported expression [%s]", s)
+	}
+	s = strings.TrimSpace(strings.TrimPrefix(s, "find"))
+	s = strings.TrimSpace(strings.TrimPrefix(s+" ", "where "))
+	if s == "" {
+		// [find] selects every item
+		return Query{}, nil
+	}
+	return parseExpression(s)
+}
+
+// parseExpression parses a where expression:
+//
+//	expr  = and { ("or" | "||") and }
+//	and   = unary { ["and" | "&&"] unary }
+//	unary = "!" unary | "(" expr ")" | name [op value]
+//
+// A bare name is a flag, disabled is disabled=true and !disabled is
+// disabled=false.
+func parseExpression(s string) (Query, error) {
+	p := &exprParser{s: s}
+	q, err := p.or()
+	if err != nil {
+		return Query{}, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.s) {
+		return Query{}, fmt.Errorf("unexpected %q in expression %q", p.s[p.pos:], s)
+	}
+	return q, nil
+}
+
+type exprParser struct {
+	s   string
+	pos int
+	// flag the name read by the last unary when it was a bare name
+	flag string
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.s) && isSpace(p.s[p.pos]) {
+		p.pos++
+	}
+}
+
+// keyword consumes one of the words when it is next
+func (p *exprParser) keyword(words ...string) bool {
+	p.skipSpace()
+	for _, w := range words {
+		if !strings.HasPrefix(p.s[p.pos:], w) {
+			continue
+		}
+		end := p.pos + len(w)
+		if isNameByte(w[0]) && end < len(p.s) && isNameByte(p.s[end]) {
+			continue
+		}
+		p.pos = end
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) or() (Query, error) {
+	q, err := p.and()
+	if err != nil {
+		return Query{}, err
+	}
+	for p.keyword("or", "||") {
+		r, err := p.and()
+		if err != nil {
+			return Query{}, err
+		}
+		q = Or(q, r)
+	}
+	return q, nil
+}
+
+func (p *exprParser) and() (Query, error) {
+	q, err := p.unary()
+	if err != nil {
+		return Query{}, err
+	}
+	for {
+		// terms next to each other are implicitly joined with and
+		if !p.keyword("and", "&&") {
+			start := p.pos
+			end := p.pos >= len(p.s) || p.s[p.pos] == ')' || p.keyword("or", "||")
+			p.pos = start
+			if end {
+				return q, nil
+			}
+		}
+		r, err := p.unary()
+		if err != nil {
+			return Query{}, err
+		}
+		q = And(q, r)
+	}
+}
+
+func (p *exprParser) unary() (Query, error) {
+	p.flag = ""
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return Query{}, fmt.Errorf("unexpected end of expression %q", p.s)
+	}
+	switch p.s[p.pos] {
+	case '!':
+		p.pos++
+		q, err := p.unary()
+		if err != nil {
+			return Query{}, err
+		}
+		if flag := p.flag; flag != "" {
+			// !name is the flag set to false
+			p.flag = ""
+			return Where(flag).Eq("false"), nil
+		}
+		return Not(q), nil
+	case '(':
+		p.pos++
+		q, err := p.or()
+		if err != nil {
+			return Query{}, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+			return Query{}, fmt.Errorf("missing ) in expression %q", p.s)
+		}
+		p.pos++
+		p.flag = ""
+		return q, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.s) && isNameByte(p.s[p.pos]) {
+		p.pos++
+	}
+	name := p.s[start:p.pos]
+	if name == "" {
+		return Query{}, fmt.Errorf("unexpected %q in expression %q", p.s[p.pos:], p.s)
+	}
+
+	var op string
+	for _, candidate := range []string{"!=", "<=", ">=", "=", "<", ">", "~"} {
+		if strings.HasPrefix(p.s[p.pos:], candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		// a bare name is a flag such as disabled or running
+		p.flag = name
+		return Where(name).Eq("true"), nil
+	}
+	p.pos += len(op)
+	value, err := p.value()
+	if err != nil {
+		return Query{}, err
+	}
+
+	field := Where(name)
+	switch op {
+	case "=":
+		return field.Eq(value), nil
+	case "!=":
+		return field.Eq(value).Not(), nil
+	case "<":
+		return field.Lt(value), nil
+	case ">":
+		return field.Gt(value), nil
+	case "<=":
+		return field.Gt(value).Not(), nil
+	case ">=":
+		return field.Lt(value).Not(), nil
+	}
+	return Query{}, fmt.Errorf("operator %s is not supported by the API in expression %q", op, p.s)
+}
+
+func (p *exprParser) value() (string, error) {
+	if p.pos < len(p.s) && p.s[p.pos] == '"' {
+		v, n, err := unquote(p.s[p.pos:])
+		if err != nil {
+			return "", err
+		}
+		p.pos += n
+		return v, nil
+	}
+	start := p.pos
+	for p.pos < len(p.s) && !isSpace(p.s[p.pos]) && p.s[p.pos] != ')' {
+		p.pos++
+	}
+	return p.s[start:p.pos], nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func isNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.'
+}
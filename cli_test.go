@@ -0,0 +1,164 @@
+package go_routeros
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/leandrose/go-routeros/routerossim"
+)
+
+func TestParseCommandLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		path  string
+		words []string
+	}{
+		{
+			"Add com espacos no menu",
+			"/ip address add address=10.0.0.1/24 interface=ether1",
+			"/ip/address/add",
+			[]string{"=address=10.0.0.1/24", "=interface=ether1"},
+		},
+		{
+			"Add com barras no menu",
+			"/ip/address/add address=10.0.0.1/24 comment=\"uplink \\\"A\\\"\"",
+			"/ip/address/add",
+			[]string{"=address=10.0.0.1/24", "=comment=uplink \"A\""},
+		},
+		{
+			"Set com find",
+			"/ip firewall filter set [find comment=\"x y\"] disabled=yes",
+			"/ip/firewall/filter/set",
+			[]string{"=disabled=yes", "?comment=x y"},
+		},
+		{
+			"Set com find where e OR",
+			"/ip firewall address-list remove [find where list=a or (list=b && !disabled)]",
+			"/ip/firewall/address-list/remove",
+			[]string{"?list=a", "?list=b", "?disabled=false", "?#&", "?#|"},
+		},
+		{
+			"Print com where",
+			"/interface print where type=ether and running name!=ether1",
+			"/interface/print",
+			[]string{"?type=ether", "?running=true", "?name=ether1", "?#!", "?#&&"},
+		},
+		{
+			"Print com flags",
+			"/ip route print count-only where distance>=2",
+			"/ip/route/print",
+			[]string{"=count-only=", "?<distance=2", "?#!"},
+		},
+		{
+			"Set por id e nome",
+			"/interface set *1 mtu=1500",
+			"/interface/set",
+			[]string{"=.id=*1", "=mtu=1500"},
+		},
+		{
+			"Unset com value-name",
+			"/interface unset ether1 comment",
+			"/interface/unset",
+			[]string{"=numbers=ether1", "=value-name=comment"},
+		},
+		{
+			"Negação de grupo com flag",
+			"/interface print where !(type=ether and running)",
+			"/interface/print",
+			[]string{"?type=ether", "?running=true", "?#&", "?#!"},
+		},
+		{
+			"Comando desconhecido com argumento nomeado",
+			"/interface monitor-traffic interface=ether1 once=yes",
+			"/interface/monitor-traffic",
+			[]string{"=interface=ether1", "=once=yes"},
+		},
+		{
+			"Comando desconhecido com barras e posicional",
+			"/interface/monitor-traffic *1 once=yes",
+			"/interface/monitor-traffic",
+			[]string{"=.id=*1", "=once=yes"},
+		},
+		{
+			"Comando desconhecido sem argumentos",
+			"/system reboot",
+			"/system/reboot",
+			nil,
+		},
+		{
+			"Comando desconhecido com find vazio",
+			"/ip dhcp-client renew [find]",
+			"/ip/dhcp-client/renew",
+			nil,
+		},
+		{
+			"Comando desconhecido de um segmento",
+			"/ping address=8.8.8.8 count=1",
+			"/ping",
+			[]string{"=address=8.8.8.8", "=count=1"},
+		},
+		{
+			"Escapes",
+			`/system identity set name="a\_b\41\\"`,
+			"/system/identity/set",
+			[]string{`=name=a bA\`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := ParseCommandLine(tt.line)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if cmd.Path != tt.path {
+				t.Errorf("expected path: %s, got: %s", tt.path, cmd.Path)
+			}
+			if !reflect.DeepEqual(cmd.Words(), tt.words) {
+				t.Errorf("expected words: %q, got: %q", tt.words, cmd.Words())
+			}
+		})
+	}
+
+	for _, line := range []string{"", `/ip address add comment="open`, "/ip address set [find comment~\"x\"]", "/ip address set [find", `/x set comment="$var"`, "/interface monitor-traffic *1", "/tool ping 8.8.8.8"} {
+		if _, err := ParseCommandLine(line); err == nil {
+			t.Errorf("expected an error for %q", line)
+		}
+	}
+}
+
+func TestRunCommandLine(t *testing.T) {
+	sim := routerossim.New()
+	sim.AddMenu("/ip/firewall/filter")
+	for _, comment := range []string{"x", "y", "x"} {
+		if _, err := sim.Add("/ip/firewall/filter", map[string]string{"chain": "input", "comment": comment}); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	client := NewClient(sim.Server().Pipe())
+	if err := client.Login("admin", ""); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.RunCommandLine(context.Background(), `/ip firewall filter set [find comment="x"] disabled=yes`); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	reply, err := client.RunCommandLine(context.Background(), "/ip firewall filter print where disabled=yes")
+	if err != nil {
+		t.Fatalf("print: %v", err)
+	}
+	if len(reply.Re) != 2 || reply.Re[0].Value("comment") != "x" || reply.Re[1].Value("comment") != "x" {
+		t.Errorf("unexpected items: %v", reply.Re)
+	}
+
+	// an empty [find] selects every item
+	if _, err := client.RunCommandLine(context.Background(), "/ip firewall filter remove [find]"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if items := sim.Items("/ip/firewall/filter"); len(items) != 0 {
+		t.Errorf("expected every item removed, got: %v", items)
+	}
+}
@@ -3,12 +3,10 @@ package go_routeros
 import (
 	"bufio"
 	"context"
-	"crypto/md5"
 	"crypto/tls"
-	"encoding/hex"
-	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net"
 	"strconv"
 	"sync"
@@ -16,70 +14,132 @@ import (
 )
 
 type Client struct {
-	ctx         context.Context
-	cancel      context.CancelFunc
-	conn        io.ReadWriteCloser
-	lock        sync.Mutex
-	reader      *bufio.Reader
-	responses   map[int]chan Response
-	nextID      int
-	debug       bool
-	loopMutex   sync.Mutex
-	loopStatus  bool
-	isConnected bool
+	ctx          context.Context
+	cancel       context.CancelFunc
+	conn         io.ReadWriteCloser
+	lock         sync.Mutex
+	writeLock    sync.Mutex
+	reader       *bufio.Reader
+	responses    map[int]*Request
+	tags         TagStrategy
+	delivery     Delivery
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+	loginTimeout time.Duration
+	loginMode    LoginMode
+	info         RouterInfo
+	debug        bool
+	logger       *slog.Logger
+	logLock      sync.RWMutex
+	redacted     []string
+	redactSet    bool
+	remote       string
+	loopMutex    sync.Mutex
+	loopStatus   bool
+	isConnected  bool
+	dial         func(ctx context.Context) (io.ReadWriteCloser, error)
+	username     string
+	password     string
+	backoff      *Backoff
+	reconnecting bool
+	deadCause    error
+
+	keepaliveCancel context.CancelFunc
 }
 
-// Dial dial
-func Dial(addr string) (*Client, error) {
-	return DialContext(context.Background(), addr)
+// Dial connects to addr, e.g. 192.168.88.1:8728, or 8729 WithTLS. The
+// connection is dialed again to reconnect, see EnableReconnect. The key of
+// the router is verified before the login, see WithPinnedKey and WithKnownHosts.
+func Dial(ctx context.Context, addr string, opts ...Option) (*Client, error) {
+	cfg := newConfig(opts)
+	dialer := cfg.dialer
+	if dialer == nil {
+		dialer = new(net.Dialer)
+	}
+	dial := func(ctx context.Context) (io.ReadWriteCloser, error) {
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+	if tlsConfig := verifiedTLSConfig(cfg, addr); tlsConfig != nil {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: tlsConfig}
+		dial = func(ctx context.Context) (io.ReadWriteCloser, error) {
+			return tlsDialer.DialContext(ctx, "tcp", addr)
+		}
+	}
+
+	conn, err := dial(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to router os: %w", err)
+	}
+	c := newClient(conn, cfg)
+	c.dial = dial
+	return c, nil
 }
 
-// DialTimeout dial with context and timeout
-func DialTimeout(duration time.Duration, addr string) (*Client, error) {
+// DialTimeout dial with timeout
+func DialTimeout(duration time.Duration, addr string, opts ...Option) (*Client, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), duration)
 	defer cancel()
-
-	return DialContext(ctx, addr)
+	return Dial(ctx, addr, opts...)
 }
 
 // DialContext dial with context
 func DialContext(ctx context.Context, addr string) (*Client, error) {
-	conn, err := (new(net.Dialer)).DialContext(ctx, "tcp", addr)
-	if err != nil {
-		return nil, fmt.Errorf("could not connect to router os: %w", err)
+	return Dial(ctx, addr)
+}
+
+// NewClient creates a client over an established connection, such as an SSH
+// channel or an in-memory pipe. Such a client can not reconnect.
+func NewClient(conn io.ReadWriteCloser, opts ...Option) *Client {
+	return newClient(conn, newConfig(opts))
+}
+
+func newClient(conn io.ReadWriteCloser, cfg *config) *Client {
+	var tags TagStrategy
+	if cfg.newTags != nil {
+		tags = cfg.newTags()
 	}
 	return &Client{
-		conn:        conn,
-		reader:      bufio.NewReader(conn),
-		responses:   make(map[int]chan Response),
-		isConnected: false,
-	}, nil
+		conn:         conn,
+		reader:       bufio.NewReader(conn),
+		responses:    make(map[int]*Request),
+		tags:         tags,
+		delivery:     cfg.delivery,
+		readTimeout:  cfg.readTimeout,
+		writeTimeout: cfg.writeTimeout,
+		loginTimeout: cfg.loginTimeout,
+		loginMode:    cfg.loginMode,
+		logger:       cfg.logger,
+		isConnected:  false,
+		remote:       remoteAddr(conn),
+	}
 }
 
-// DialTLS dial
+// DialTLS dial with TLS
 func DialTLS(address string, tlsConfig *tls.Config) (*Client, error) {
-	return DialTLSContext(context.Background(), address, tlsConfig)
+	return Dial(context.Background(), address, WithTLS(tlsConfig))
 }
 
-// DialTLSTimeout dial with context and timeout
-func DialTLSTimeout(address string, tlsConfig *tls.Config) (*Client, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	return DialTLSContext(ctx, address, tlsConfig)
+// DialTLSTimeout dial with TLS and timeout
+func DialTLSTimeout(duration time.Duration, address string, tlsConfig *tls.Config) (*Client, error) {
+	return DialTimeout(duration, address, WithTLS(tlsConfig))
 }
 
-// DialTLSContext dial with context
+// DialTLSContext dial with TLS and context
 func DialTLSContext(ctx context.Context, address string, tlsConfig *tls.Config) (*Client, error) {
-	conn, err := (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", address)
-	if err != nil {
-		return nil, fmt.Errorf("could not connect to router os: %w", err)
+	return Dial(ctx, address, WithTLS(tlsConfig))
+}
+
+// nextTag returns a tag that no running command uses, c.lock must be held
+func (c *Client) nextTag() int {
+	if c.tags == nil {
+		c.tags = SequentialTags(0)
+	}
+	for {
+		tag := c.tags()
+		if _, ok := c.responses[tag]; !ok {
+			return tag
+		}
 	}
-	return &Client{
-		conn:        conn,
-		reader:      bufio.NewReader(conn),
-		responses:   make(map[int]chan Response),
-		isConnected: false,
-	}, nil
 }
 
 // Close the connection
@@ -89,107 +149,50 @@ func (c *Client) Close() {
 	if c.cancel != nil {
 		c.cancel()
 	}
+	if c.keepaliveCancel != nil {
+		c.keepaliveCancel()
+		c.keepaliveCancel = nil
+	}
 	c.isConnected = false
 	if c.conn != nil {
 		_ = c.conn.Close()
 	}
-	for k, ch := range c.responses {
-		close(ch)
+	for k, req := range c.responses {
 		delete(c.responses, k)
-	}
-}
-
-// Login to routeros
-func (c *Client) Login(username, password string) error {
-	sent := []string{
-		"/login",
-		fmt.Sprintf("=name=%s", username),
-		fmt.Sprintf("=password=%s", password),
-	}
-	if err := c.writeSentence(sent); err != nil {
-		return err
-	}
-
-	for {
-		sentence, err := c.readSentence()
-		if err != nil {
-			return err
-		}
-		if c.debug {
-			fmt.Printf("DEBUG LOGIN: %+v\n", sentence)
-		}
-		if v, ok := sentence["ret"]; ok {
-			var dec []byte
-			if dec, err = hex.DecodeString(v); err != nil {
-				return err
-			}
-			sent = []string{
-				"/login",
-				fmt.Sprintf("=name=%s", username),
-				fmt.Sprintf("=response=%s", c.challengeResponse(dec, password)),
-			}
-			if err := c.writeSentence(sent); err != nil {
-				return err
-			}
-			sentence, err = c.readSentence()
-			if err != nil {
-				return err
-			}
-			if c.debug {
-				fmt.Printf("DEBUG LOGIN: %+v\n", sentence)
-			}
-		}
-		if v, ok := sentence["!type"]; ok {
-			if v == "!done" {
-				c.isConnected = true
-				c.startReadLoop()
-				return nil
-			}
-			if sentence["!type"] == "!trap" || sentence["!type"] == "!fatal" {
-				return errors.New(sentence["=message"])
-			}
-		}
+		req.close(nil)
 	}
 }
 
 // IsConnected check if the client is connected
 func (c *Client) IsConnected() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 	return c.isConnected
 }
 
-// SendCommand sends a command to RouterOS and returns a channel to receive responses
-func (c *Client) SendCommand(cmd string, args ...string) (chan Response, error) {
+// inFlight returns the number of running commands
+func (c *Client) inFlight() int {
 	c.lock.Lock()
-	id := c.nextID
-	c.nextID++
-	ch := make(chan Response, 10)
-	c.responses[id] = ch
-	c.lock.Unlock()
-
-	fullCmd := []string{cmd}
-	fullCmd = append(fullCmd, args...)
-	fullCmd = append(fullCmd, fmt.Sprintf(".tag=%d", id))
+	defer c.lock.Unlock()
+	return len(c.responses)
+}
 
-	err := c.writeSentence(fullCmd)
+// SendCommand sends a command to RouterOS and returns a channel to receive responses
+func (c *Client) SendCommand(cmd string, args ...string) (chan Response, error) {
+	req, err := c.SendCommandContext(context.Background(), cmd, args...)
 	if err != nil {
 		return nil, err
 	}
-
-	return ch, nil
+	return req.ch, nil
 }
 
 // sendErrorAllResponses send an error response to all channels
 func (c *Client) sendErrorAllResponses(err error) {
-	for _, ch := range c.responses {
-		ch <- Response{
-			Err: &RouterOSError{
-				message: err.Error(),
-			},
-			Type: "!fatal",
-			Data: map[string]string{
-				"message": err.Error(),
-			},
-		}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for k, req := range c.responses {
+		delete(c.responses, k)
+		req.close(fatalResponse(newConnectionError(err)))
 	}
 }
 
@@ -228,17 +231,27 @@ func (c *Client) readLoop() {
 		default:
 			sentence, err := c.readSentence()
 			if err != nil {
-				if errors.Is(err, io.EOF) {
-					c.sendErrorAllResponses(err)
-					c.Close()
+				if c.ctx.Err() != nil {
 					return
 				}
-				time.Sleep(100 * time.Millisecond)
-				continue
+				err = c.connectionError(err)
+				if c.reconnect(err) {
+					continue
+				}
+				if logger := c.log(); logger != nil {
+					logger.Warn("connection closed", "error", err)
+				}
+				c.sendErrorAllResponses(err)
+				c.Close()
+				return
 			}
 
-			tag := sentence[".tag"]
+			tag := sentence.Tag()
 			if tag == "" {
+				// RouterOS sends an untagged !fatal before closing the connection
+				if sentence.Word == "!fatal" {
+					c.markDead(newRouterOSError(sentence, nil))
+				}
 				continue
 			}
 
@@ -248,7 +261,7 @@ func (c *Client) readLoop() {
 			}
 
 			c.lock.Lock()
-			ch, ok := c.responses[int(id)]
+			req, ok := c.responses[int(id)]
 			c.lock.Unlock()
 
 			if !ok {
@@ -256,45 +269,21 @@ func (c *Client) readLoop() {
 			}
 
 			response := Response{
-				Type: sentence["!type"],
-				Data: sentence,
+				Type:     sentence.Word,
+				Sentence: sentence,
+				Data:     sentence.Map(),
 			}
 
 			if response.Type == "!trap" || response.Type == "!fatal" {
-				errRouterOS := RouterOSError{}
-				if e, ok := response.Data["message"]; ok {
-					errRouterOS.message = e
-				} else {
-					errRouterOS.message = "an error occurred"
-				}
-				response.Err = &errRouterOS
+				response.Err = newRouterOSError(sentence, req.words)
 			}
 
-			ch <- response
-
+			// replies of a cancelled command are drained until it finishes
 			if response.Type == "!done" || response.Type == "!trap" || response.Type == "!fatal" || response.Type == "!empty" {
-				c.lock.Lock()
-				close(ch)
-				delete(c.responses, int(id))
-				c.lock.Unlock()
+				c.finish(req, &response)
+			} else {
+				req.push(response)
 			}
 		}
 	}
 }
-
-func (c *Client) EnableDebug() {
-	c.debug = true
-}
-
-func (c *Client) DisableDebug() {
-	c.debug = false
-}
-
-// challengeResponse - prepare MD5 hash for auth challenge response
-func (c *Client) challengeResponse(cha []byte, password string) string {
-	h := md5.New() //nolint:gosec
-	h.Write([]byte{0})
-	h.Write([]byte(password))
-	h.Write(cha)
-	return fmt.Sprintf("00%x", h.Sum(nil))
-}
@@ -0,0 +1,73 @@
+package go_routeros
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/leandrose/go-routeros/routerostest"
+)
+
+func newTestClient(t *testing.T, server *routerostest.Server) *Client {
+	t.Helper()
+	client := NewClient(server.Pipe())
+	if err := client.Login(server.Username, server.Password); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		style    routerostest.LoginStyle
+		password string
+		fail     bool
+	}{
+		{"Plain", routerostest.LoginPlain, "secret", false},
+		{"Challenge", routerostest.LoginChallenge, "secret", false},
+		{"Invalid password", routerostest.LoginPlain, "wrong", true},
+		{"Invalid challenge response", routerostest.LoginChallenge, "wrong", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := routerostest.NewServer()
+			server.Password = "secret"
+			server.LoginStyle = tt.style
+
+			client := NewClient(server.Pipe())
+			defer client.Close()
+			err := client.Login("admin", tt.password)
+			if tt.fail != (err != nil) {
+				t.Fatalf("unexpected login result: %v", err)
+			}
+			if client.IsConnected() == tt.fail {
+				t.Errorf("unexpected connected state: %v", client.IsConnected())
+			}
+		})
+	}
+}
+
+func TestFatalFailsPendingCommands(t *testing.T) {
+	server := routerostest.NewServer()
+	server.Reply("/interface/listen", routerostest.Block())
+	server.Reply("/quit", routerostest.Fatal("session terminated on request"))
+	client := newTestClient(t, server)
+
+	req, err := client.SendCommandContext(context.Background(), "/interface/listen")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	_, _ = client.SendCommand("/quit")
+
+	var last Response
+	for response := range req.Responses() {
+		last = response
+	}
+	var rosErr *RouterOSError
+	if !errors.As(last.Err, &rosErr) || !rosErr.Fatal || rosErr.Message != "session terminated on request" {
+		t.Errorf("expected the !fatal message, got: %v", last.Err)
+	}
+}
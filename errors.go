@@ -1,9 +1,143 @@
 package go_routeros
 
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrClosed is returned when the connection is closed before a command finishes
+var ErrClosed = errors.New("connection closed")
+
+// Sentinel errors matched by RouterOSError with errors.Is
+var (
+	// ErrNoSuchItem the item or command referenced does not exist
+	ErrNoSuchItem = errors.New("no such item")
+	// ErrAlreadyExists an item with the same unique value already exists
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrNotPermitted the user is not allowed to run the command
+	ErrNotPermitted = errors.New("not permitted")
+	// ErrInterrupted the command was interrupted, e.g. by /cancel
+	ErrInterrupted = errors.New("interrupted")
+)
+
+// TrapCategory is the =category= sent by RouterOS with a !trap
+type TrapCategory int
+
+const (
+	// CategoryUnknown the !trap carried no category
+	CategoryUnknown TrapCategory = iota - 1
+	// CategoryMissingItem missing item or command
+	CategoryMissingItem
+	// CategoryArgumentValue argument value failure
+	CategoryArgumentValue
+	// CategoryInterrupted execution of command interrupted
+	CategoryInterrupted
+	// CategoryScripting scripting related failure
+	CategoryScripting
+	// CategoryGeneral general failure
+	CategoryGeneral
+	// CategoryAPI API related failure
+	CategoryAPI
+	// CategoryTTY TTY related failure
+	CategoryTTY
+	// CategoryReturnValue value generated with :return command
+	CategoryReturnValue
+)
+
+var categoryNames = [...]string{
+	"missing item or command",
+	"argument value failure",
+	"execution of command interrupted",
+	"scripting related failure",
+	"general failure",
+	"API related failure",
+	"TTY related failure",
+	"value generated with :return command",
+}
+
+func (c TrapCategory) String() string {
+	if c >= 0 && int(c) < len(categoryNames) {
+		return categoryNames[c]
+	}
+	return "unknown"
+}
+
+// RouterOSError is an error reported by RouterOS with !trap or !fatal, or a
+// connection failure reported to pending commands as !fatal
 type RouterOSError struct {
-	message string
+	// Fatal the error came from a !fatal reply, the connection is closed
+	Fatal bool
+	// Category the =category= of a !trap, CategoryUnknown when absent
+	Category TrapCategory
+	// Message the =message= of the reply
+	Message string
+	// Sentence the full reply sentence
+	Sentence Sentence
+	// Command the command that caused the error
+	Command string
+	// Args the arguments of the command
+	Args []string
+
+	err error
+}
+
+// newRouterOSError builds the error for a !trap or !fatal reply of words
+func newRouterOSError(sentence Sentence, words []string) *RouterOSError {
+	e := &RouterOSError{
+		Fatal:    sentence.Word == "!fatal",
+		Category: CategoryUnknown,
+		Message:  "an error occurred",
+		Sentence: sentence,
+	}
+	if v, ok := sentence.Get("message"); ok {
+		e.Message = v
+	}
+	if v, ok := sentence.Get("category"); ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			e.Category = TrapCategory(n)
+		}
+	}
+	if len(words) > 0 {
+		e.Command = words[0]
+		e.Args = words[1:]
+	}
+	return e
+}
+
+// newConnectionError builds the !fatal error reported when the connection fails
+func newConnectionError(err error) *RouterOSError {
+	return &RouterOSError{
+		Fatal:    true,
+		Category: CategoryUnknown,
+		Message:  err.Error(),
+		err:      err,
+	}
 }
 
 func (e *RouterOSError) Error() string {
-	return e.message
+	return e.Message
+}
+
+// Unwrap returns the underlying error of a connection failure
+func (e *RouterOSError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether the error matches one of the sentinel errors
+func (e *RouterOSError) Is(target error) bool {
+	message := strings.ToLower(e.Message)
+	switch target {
+	case ErrNoSuchItem:
+		return e.Category == CategoryMissingItem || strings.Contains(message, "no such item")
+	case ErrAlreadyExists:
+		return strings.Contains(message, "already have") || strings.Contains(message, "already exists")
+	case ErrNotPermitted:
+		return strings.Contains(message, "not enough permissions") ||
+			strings.Contains(message, "permission denied") ||
+			strings.Contains(message, "not permitted")
+	case ErrInterrupted:
+		return e.Category == CategoryInterrupted || message == "interrupted"
+	}
+	return false
 }
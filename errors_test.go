@@ -0,0 +1,39 @@
+package go_routeros
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRouterOSErrorIs(t *testing.T) {
+	tests := []struct {
+		name     string
+		words    []string
+		target   error
+		category TrapCategory
+	}{
+		{"No such item", []string{"!trap", "=message=no such item"}, ErrNoSuchItem, CategoryUnknown},
+		{"Missing command", []string{"!trap", "=category=0", "=message=no such command"}, ErrNoSuchItem, CategoryMissingItem},
+		{"Already exists", []string{"!trap", "=message=failure: already have such address"}, ErrAlreadyExists, CategoryUnknown},
+		{"Not permitted", []string{"!trap", "=message=not enough permissions (9)"}, ErrNotPermitted, CategoryUnknown},
+		{"Interrupted", []string{"!trap", "=category=2", "=message=interrupted"}, ErrInterrupted, CategoryInterrupted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newRouterOSError(parseSentence(tt.words), []string{"/ip/address/add", "=address=10.0.0.1/24"})
+			if !errors.Is(err, tt.target) {
+				t.Errorf("expected errors.Is(%v, %v)", err, tt.target)
+			}
+			if err.Category != tt.category {
+				t.Errorf("expected category %v, got: %v", tt.category, err.Category)
+			}
+			if err.Command != "/ip/address/add" || len(err.Args) != 1 {
+				t.Errorf("expected the command context, got: %s %v", err.Command, err.Args)
+			}
+			if err.Fatal {
+				t.Errorf("a !trap must not be fatal")
+			}
+		})
+	}
+}
@@ -27,7 +27,11 @@ func init() {
 func main() {
     ctx, cancel := context.WithTimeout(context.Background(), *timeout)
     defer cancel()
-    client, err := go_routeros.DialContext(ctx, *address)
+    var opts []go_routeros.Option
+    if *useTLS {
+        opts = append(opts, go_routeros.WithTLS(nil))
+    }
+    client, err := go_routeros.Dial(ctx, *address, opts...)
     if err != nil {
         panic(err.Error())
     }
@@ -41,7 +45,9 @@ func main() {
 
     wg := sync.WaitGroup{}
 
-    responses, err := client.SendCommand("/interface/listen")
+    // the watcher lists the interfaces, then follows /interface/listen until
+    // the timeout expires
+    watcher, err := client.Watch(ctx, "/interface", go_routeros.Query{})
     if err != nil {
         panic(err.Error())
     }
@@ -49,37 +55,19 @@ func main() {
     go func() {
         defer func() {
             wg.Done()
-            fmt.Printf("responses: finish\n")
+            fmt.Printf("events: finish\n")
         }()
-        i := 0
-    loop:
-        for {
-            select {
-            case response, ok := <-responses:
-                if !ok {
-                    fmt.Println("responses: close")
-                    break loop
-                }
-
-                switch response.Type {
-                case "!re":
-                    i++
-                    if v, ok := response.Data["=name"]; ok {
-                        fmt.Printf("secret name = %s\n", v)
-                    }
-                case "!done":
-                    fmt.Println("responses: done")
-                    break loop
-                case "!empty":
-                    fmt.Println("responses: empty")
-                    break loop
-                case "!trap", "!fatal":
-                    fmt.Printf("Error in Mikrotik: %v\n", response.Data["message"])
-                    break loop
-                }
+        for event := range watcher.Events() {
+            switch event.Type {
+            case go_routeros.Added:
+                fmt.Printf("interface added: %s\n", event.Item.Value("name"))
+            case go_routeros.Updated:
+                fmt.Printf("interface updated: %s %v\n", event.Item.Value("name"), event.Changed)
+            case go_routeros.Removed:
+                fmt.Printf("interface removed: %s\n", event.Item.Value("name"))
             }
         }
-        fmt.Printf("it was read %d secrets\n", i)
+        fmt.Printf("watcher stopped: %v\n", watcher.Err())
     }()
 
     wg.Wait()
@@ -27,7 +27,11 @@ func init() {
 func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
-	client, err := go_routeros.DialContext(ctx, *address)
+	var opts []go_routeros.Option
+	if *useTLS {
+		opts = append(opts, go_routeros.WithTLS(nil))
+	}
+	client, err := go_routeros.Dial(ctx, *address, opts...)
 	if err != nil {
 		panic(err.Error())
 	}
@@ -65,7 +69,7 @@ func main() {
 
 				switch response.Type {
 				case "!re":
-					if v, ok := response.Data["=name"]; ok {
+					if v, ok := response.Sentence.Get("name"); ok {
 						fmt.Printf("secret name = %s\n", v)
 					}
 				case "!done":
@@ -73,7 +77,7 @@ func main() {
 				case "!empty":
 					fmt.Println("cmd2: empty")
 				case "!trap", "!fatal":
-					fmt.Printf("Error in Mikrotik: %v\n", response.Data["=message"])
+					fmt.Printf("Error in Mikrotik: %v\n", response.Sentence.Value("message"))
 				}
 			}
 		}
@@ -94,7 +98,7 @@ func main() {
 
 				switch response.Type {
 				case "!re":
-					if v, ok := response.Data["=name"]; ok {
+					if v, ok := response.Sentence.Get("name"); ok {
 						fmt.Printf("user name = %s\n", v)
 					}
 				case "!done":
@@ -102,7 +106,7 @@ func main() {
 				case "!empty":
 					fmt.Println("cmd2: empty")
 				case "!trap", "!fatal":
-					fmt.Printf("Error in Mikrotik: %v\n", response.Data["=message"])
+					fmt.Printf("Error in Mikrotik: %v\n", response.Sentence.Value("message"))
 				}
 			}
 		}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	go_routeros "github.com/leandrose/go-routeros"
@@ -27,7 +28,11 @@ func init() {
 func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
-	client, err := go_routeros.DialContext(ctx, *address)
+	var opts []go_routeros.Option
+	if *useTLS {
+		opts = append(opts, go_routeros.WithTLS(nil))
+	}
+	client, err := go_routeros.Dial(ctx, *address, opts...)
 	if err != nil {
 		panic(err.Error())
 	}
@@ -53,33 +58,17 @@ func main() {
 		[]string{"/system/resource/print", "=disabled=yess"}, // error
 	}
 
-	i := 0
-	for _, cmd := range cmds {
-		i++
-		responses, err := client.SendCommand(cmd[0], cmd[1:]...)
-		if err != nil {
-			panic(err)
-		}
-
-	loop:
-		for {
-			select {
-			case resp, ok := <-responses:
-				if !ok {
-					break loop
-				}
-				switch resp.Type {
-				case "!done":
-					if !(i == 1 || i == 2) {
-						panic(fmt.Sprintf("an error occurred in command: cmd=%s", strings.Join(cmd, " ")))
-					}
-				case "!trap":
-					if i != 3 {
-						panic(fmt.Sprintf("an error occurred in command: cmd=%s", strings.Join(cmd, " ")))
-					} else {
-						fmt.Printf("an error expected occurred in command: cmd=%s message=%s\n", strings.Join(cmd, " "), resp.Err.Error())
-					}
-				}
+	for i, cmd := range cmds {
+		_, err := client.Run(ctx, cmd[0], cmd[1:]...)
+		switch {
+		case err == nil && i == 2:
+			panic(fmt.Sprintf("an error was expected in command: cmd=%s", strings.Join(cmd, " ")))
+		case err != nil && i != 2:
+			panic(fmt.Sprintf("an error occurred in command: cmd=%s err=%v", strings.Join(cmd, " "), err))
+		case err != nil:
+			var rosErr *go_routeros.RouterOSError
+			if errors.As(err, &rosErr) {
+				fmt.Printf("an error expected occurred in command: cmd=%s category=%s message=%s\n", strings.Join(cmd, " "), rosErr.Category, rosErr.Message)
 			}
 		}
 	}
@@ -26,7 +26,11 @@ func init() {
 func main() {
     ctx, cancel := context.WithTimeout(context.Background(), *timeout)
     defer cancel()
-    client, err := go_routeros.DialContext(ctx, *address)
+    var opts []go_routeros.Option
+    if *useTLS {
+        opts = append(opts, go_routeros.WithTLS(nil))
+    }
+    client, err := go_routeros.Dial(ctx, *address, opts...)
     if err != nil {
         panic(err.Error())
     }
@@ -38,48 +42,38 @@ func main() {
     }
     fmt.Printf("mikrotik logged\n")
 
+    // the informers follow the menus with print and listen, the ticker below
+    // reads the local copy without sending commands
+    cache := go_routeros.NewCache(client)
+    leases := cache.Informer("/ip/dhcp-server/lease")
+    if err = leases.AddIndex("mac-address", go_routeros.AttributeIndex("mac-address")); err != nil {
+        panic(err.Error())
+    }
+    active := cache.Informer("/ppp/active")
+    active.AddEventHandler(go_routeros.EventHandlerFuncs{
+        AddFunc: func(item go_routeros.Sentence) {
+            fmt.Printf("ppp connected: %s\n", item.Value("name"))
+        },
+        DeleteFunc: func(item go_routeros.Sentence) {
+            fmt.Printf("ppp disconnected: %s\n", item.Value("name"))
+        },
+    })
+    cache.Start(ctx)
+    if err = cache.WaitForSync(ctx); err != nil {
+        panic(err.Error())
+    }
+
     ticker := time.NewTicker(5 * time.Second)
+    defer ticker.Stop()
     for {
         select {
+        case <-ctx.Done():
+            return
         case <-ticker.C:
-            responses, err := client.SendCommand("/ppp/secret/print")
-            if err != nil {
-                panic(err.Error())
+            for _, lease := range leases.List() {
+                fmt.Printf("lease %s = %s\n", lease.Value("mac-address"), lease.Value("address"))
             }
-            go func() {
-                defer func() {
-                    fmt.Printf("responses: finish\n")
-                }()
-                i := 0
-            loop:
-                for {
-                    select {
-                    case response, ok := <-responses:
-                        if !ok {
-                            fmt.Println("responses: close")
-                            break loop
-                        }
-
-                        switch response.Type {
-                        case "!re":
-                            i++
-                            if v, ok := response.Data["=name"]; ok {
-                                fmt.Printf("secret name = %s\n", v)
-                            }
-                        case "!done":
-                            fmt.Println("responses: done")
-                            break loop
-                        case "!empty":
-                            fmt.Println("responses: empty")
-                            break loop
-                        case "!trap", "!fatal":
-                            fmt.Printf("Error in Mikrotik: %v\n", response.Data["message"])
-                            break loop
-                        }
-                    }
-                }
-                fmt.Printf("it was read %d secrets\n", i)
-            }()
+            fmt.Printf("%d leases, %d ppp sessions\n", len(leases.List()), len(active.List()))
         }
     }
 }
@@ -0,0 +1,368 @@
+package go_routeros
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrInformerStarted is returned when an informer is run twice
+var ErrInformerStarted = errors.New("informer already started")
+
+// IndexFunc returns the keys under which an item is indexed, none to leave
+// it out of the index
+type IndexFunc func(item Sentence) []string
+
+// AttributeIndex indexes items by the value of the attribute, such as
+// mac-address, name or comment. Items without a value are not indexed.
+func AttributeIndex(name string) IndexFunc {
+	return func(item Sentence) []string {
+		if v := item.Value(name); v != "" {
+			return []string{v}
+		}
+		return nil
+	}
+}
+
+// EventHandler receives the changes applied to an informer
+type EventHandler interface {
+	OnAdd(item Sentence)
+	OnUpdate(old, new Sentence)
+	OnDelete(item Sentence)
+}
+
+// EventHandlerFuncs adapts functions to an EventHandler, nil functions are skipped
+type EventHandlerFuncs struct {
+	AddFunc    func(item Sentence)
+	UpdateFunc func(old, new Sentence)
+	DeleteFunc func(item Sentence)
+}
+
+// OnAdd calls AddFunc
+func (f EventHandlerFuncs) OnAdd(item Sentence) {
+	if f.AddFunc != nil {
+		f.AddFunc(item)
+	}
+}
+
+// OnUpdate calls UpdateFunc
+func (f EventHandlerFuncs) OnUpdate(old, new Sentence) {
+	if f.UpdateFunc != nil {
+		f.UpdateFunc(old, new)
+	}
+}
+
+// OnDelete calls DeleteFunc
+func (f EventHandlerFuncs) OnDelete(item Sentence) {
+	if f.DeleteFunc != nil {
+		f.DeleteFunc(item)
+	}
+}
+
+// Informer keeps a thread-safe copy of the items of a menu, kept up to date by
+// a Watcher. Any number of goroutines can read it without sending commands.
+type Informer struct {
+	client *Client
+	menu   string
+	query  Query
+
+	// handlersMu serializes the changes and the handlers, so a handler added
+	// late sees every item exactly once
+	handlersMu sync.Mutex
+	handlers   []EventHandler
+
+	mu       sync.RWMutex
+	items    map[string]Sentence
+	indexers map[string]IndexFunc
+	indexes  map[string]map[string]map[string]struct{}
+	started  bool
+	err      error
+	synced   chan struct{}
+	done     chan struct{}
+}
+
+// NewInformer returns an informer of the items of menu matching query, it
+// starts following the menu when Run is called
+func (c *Client) NewInformer(menu string, query Query) *Informer {
+	return &Informer{
+		client:   c,
+		menu:     menu,
+		query:    query,
+		items:    make(map[string]Sentence),
+		indexers: make(map[string]IndexFunc),
+		indexes:  make(map[string]map[string]map[string]struct{}),
+		synced:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Menu returns the menu of the informer, e.g. /ip/dhcp-server/lease
+func (i *Informer) Menu() string {
+	return i.menu
+}
+
+// AddIndex adds a secondary index, the items already known are indexed
+func (i *Informer) AddIndex(name string, f IndexFunc) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if _, ok := i.indexers[name]; ok {
+		return fmt.Errorf("index %q already exists", name)
+	}
+	i.indexers[name] = f
+	i.indexes[name] = make(map[string]map[string]struct{})
+	for id, item := range i.items {
+		i.index(name, f, id, item)
+	}
+	return nil
+}
+
+// AddEventHandler registers a handler, OnAdd is called first for every item
+// already known. Handlers are called one at a time from the informer.
+func (i *Informer) AddEventHandler(h EventHandler) {
+	i.handlersMu.Lock()
+	defer i.handlersMu.Unlock()
+	for _, item := range i.List() {
+		h.OnAdd(item)
+	}
+	i.handlers = append(i.handlers, h)
+}
+
+// Run follows the menu until ctx is done or the watcher fails, it returns nil
+// when ctx is done
+func (i *Informer) Run(ctx context.Context) error {
+	i.mu.Lock()
+	if i.started {
+		i.mu.Unlock()
+		return ErrInformerStarted
+	}
+	i.started = true
+	i.mu.Unlock()
+
+	err := i.run(ctx)
+	if ctx.Err() != nil {
+		err = nil
+	}
+	i.mu.Lock()
+	i.err = err
+	i.mu.Unlock()
+	close(i.done)
+	return err
+}
+
+func (i *Informer) run(ctx context.Context) error {
+	w, err := i.client.Watch(ctx, i.menu, i.query)
+	if err != nil {
+		return err
+	}
+	synced := w.Synced()
+	for {
+		select {
+		case <-synced:
+			close(i.synced)
+			synced = nil
+		case e, ok := <-w.Events():
+			if !ok {
+				return w.Err()
+			}
+			i.apply(e)
+		}
+	}
+}
+
+// apply stores the change and calls the handlers
+func (i *Informer) apply(e Event) {
+	i.handlersMu.Lock()
+	defer i.handlersMu.Unlock()
+
+	i.mu.Lock()
+	old, ok := i.items[e.ID]
+	if ok {
+		i.unindex(e.ID, old)
+	}
+	if e.Type == Removed {
+		delete(i.items, e.ID)
+	} else {
+		i.items[e.ID] = e.Item
+		for name, f := range i.indexers {
+			i.index(name, f, e.ID, e.Item)
+		}
+	}
+	i.mu.Unlock()
+
+	for _, h := range i.handlers {
+		switch {
+		case e.Type == Removed:
+			h.OnDelete(e.Item)
+		case ok:
+			h.OnUpdate(old, e.Item)
+		default:
+			h.OnAdd(e.Item)
+		}
+	}
+}
+
+func (i *Informer) index(name string, f IndexFunc, id string, item Sentence) {
+	for _, key := range f(item) {
+		ids, ok := i.indexes[name][key]
+		if !ok {
+			ids = make(map[string]struct{})
+			i.indexes[name][key] = ids
+		}
+		ids[id] = struct{}{}
+	}
+}
+
+func (i *Informer) unindex(id string, item Sentence) {
+	for name, f := range i.indexers {
+		for _, key := range f(item) {
+			delete(i.indexes[name][key], id)
+			if len(i.indexes[name][key]) == 0 {
+				delete(i.indexes[name], key)
+			}
+		}
+	}
+}
+
+// HasSynced reports whether the first listing of the menu was applied
+func (i *Informer) HasSynced() bool {
+	select {
+	case <-i.synced:
+		return true
+	default:
+		return false
+	}
+}
+
+// WaitForSync waits until the first listing of the menu was applied, it fails
+// when Run stops before
+func (i *Informer) WaitForSync(ctx context.Context) error {
+	select {
+	case <-i.synced:
+		return nil
+	case <-i.done:
+		if err := i.Err(); err != nil {
+			return err
+		}
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Err returns why Run stopped, or nil
+func (i *Informer) Err() error {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.err
+}
+
+// Get returns the item with the .id
+func (i *Informer) Get(id string) (Sentence, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	item, ok := i.items[id]
+	return item, ok
+}
+
+// List returns every item, ordered by .id
+func (i *Informer) List() []Sentence {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	items := make([]Sentence, 0, len(i.items))
+	for _, item := range i.items {
+		items = append(items, item)
+	}
+	sortItems(items)
+	return items
+}
+
+// ByIndex returns the items indexed under key, ordered by .id
+func (i *Informer) ByIndex(name, key string) ([]Sentence, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	index, ok := i.indexes[name]
+	if !ok {
+		return nil, fmt.Errorf("index %q does not exist", name)
+	}
+	items := make([]Sentence, 0, len(index[key]))
+	for id := range index[key] {
+		items = append(items, i.items[id])
+	}
+	sortItems(items)
+	return items, nil
+}
+
+// Cache shares one informer per menu between the goroutines of a program
+type Cache struct {
+	client *Client
+
+	mu        sync.Mutex
+	informers map[string]*Informer
+	ctx       context.Context
+}
+
+// NewCache returns an empty cache of the menus of the client
+func NewCache(client *Client) *Cache {
+	return &Cache{
+		client:    client,
+		informers: make(map[string]*Informer),
+	}
+}
+
+// Informer returns the informer of the whole menu, it is created on first use
+// and runs right away when the cache was started
+func (c *Cache) Informer(menu string) *Informer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if i, ok := c.informers[menu]; ok {
+		return i
+	}
+	i := c.client.NewInformer(menu, Query{})
+	c.informers[menu] = i
+	if c.ctx != nil {
+		go func() {
+			_ = i.Run(c.ctx)
+		}()
+	}
+	return i
+}
+
+// Start runs the informers of the cache until ctx is done
+func (c *Cache) Start(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ctx != nil {
+		return
+	}
+	c.ctx = ctx
+	for _, i := range c.informers {
+		go func(i *Informer) {
+			_ = i.Run(ctx)
+		}(i)
+	}
+}
+
+// WaitForSync waits until every informer of the cache has synced
+func (c *Cache) WaitForSync(ctx context.Context) error {
+	c.mu.Lock()
+	informers := make([]*Informer, 0, len(c.informers))
+	for _, i := range c.informers {
+		informers = append(informers, i)
+	}
+	c.mu.Unlock()
+	for _, i := range informers {
+		if err := i.WaitForSync(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortItems orders items by .id
+func sortItems(items []Sentence) {
+	sort.Slice(items, func(a, b int) bool {
+		return lessID(items[a].Value(".id"), items[b].Value(".id"))
+	})
+}
@@ -0,0 +1,169 @@
+package go_routeros
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/leandrose/go-routeros/routerossim"
+)
+
+func TestInformer(t *testing.T) {
+	sim := routerossim.New()
+	sim.AddMenu("/ip/dhcp-server/lease")
+	for _, mac := range []string{"AA:AA:AA:AA:AA:01", "AA:AA:AA:AA:AA:02"} {
+		if _, err := sim.Add("/ip/dhcp-server/lease", map[string]string{"mac-address": mac, "comment": "office"}); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	client := NewClient(sim.Server().Pipe())
+	if err := client.Login("admin", ""); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	informer := client.NewInformer("/ip/dhcp-server/lease", Query{})
+	if err := informer.AddIndex("mac-address", AttributeIndex("mac-address")); err != nil {
+		t.Fatalf("index: %v", err)
+	}
+	if err := informer.AddIndex("mac-address", AttributeIndex("mac-address")); err == nil {
+		t.Errorf("expected an error for a duplicated index")
+	}
+	if informer.HasSynced() {
+		t.Errorf("expected the informer not to be synced before Run")
+	}
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- informer.Run(ctx)
+	}()
+	if err := informer.WaitForSync(ctx); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if !informer.HasSynced() || len(informer.List()) != 2 {
+		t.Fatalf("expected 2 synced leases, got: %v", informer.List())
+	}
+
+	// the comment index is added late, the known items are indexed
+	if err := informer.AddIndex("comment", AttributeIndex("comment")); err != nil {
+		t.Fatalf("index: %v", err)
+	}
+	if items, _ := informer.ByIndex("comment", "office"); len(items) != 2 {
+		t.Errorf("expected 2 leases of the office, got: %v", items)
+	}
+	if _, err := informer.ByIndex("name", "x"); err == nil {
+		t.Errorf("expected an error for an unknown index")
+	}
+
+	type change struct {
+		kind string
+		item Sentence
+	}
+	changes := make(chan change, 10)
+	informer.AddEventHandler(EventHandlerFuncs{
+		AddFunc: func(item Sentence) {
+			changes <- change{"add", item}
+		},
+		UpdateFunc: func(old, new Sentence) {
+			changes <- change{"update", new}
+		},
+		DeleteFunc: func(item Sentence) {
+			changes <- change{"delete", item}
+		},
+	})
+	next := func(kind string) Sentence {
+		t.Helper()
+		select {
+		case c := <-changes:
+			if c.kind != kind {
+				t.Fatalf("expected %s, got: %s %v", kind, c.kind, c.item)
+			}
+			return c.item
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for %s", kind)
+		}
+		return Sentence{}
+	}
+	// the handler receives the known items first
+	next("add")
+	next("add")
+
+	reply, err := client.Run(ctx, "/ip/dhcp-server/lease/add", "=mac-address=AA:AA:AA:AA:AA:03", "=comment=guest")
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	id := reply.Ret()
+	if item := next("add"); item.Value(".id") != id {
+		t.Errorf("expected the new lease, got: %v", item)
+	}
+	if items, _ := informer.ByIndex("mac-address", "AA:AA:AA:AA:AA:03"); len(items) != 1 || items[0].Value(".id") != id {
+		t.Errorf("expected the lease by its mac-address, got: %v", items)
+	}
+
+	if _, err := client.Run(ctx, "/ip/dhcp-server/lease/set", "=.id="+id, "=comment=office"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	next("update")
+	if items, _ := informer.ByIndex("comment", "office"); len(items) != 3 {
+		t.Errorf("expected 3 leases of the office, got: %v", items)
+	}
+	if items, _ := informer.ByIndex("comment", "guest"); len(items) != 0 {
+		t.Errorf("expected the old key to be unindexed, got: %v", items)
+	}
+
+	if _, err := client.Run(ctx, "/ip/dhcp-server/lease/remove", "=.id="+id); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	next("delete")
+	if _, ok := informer.Get(id); ok {
+		t.Errorf("expected the lease to be removed")
+	}
+
+	cancel()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("expected Run to return nil, got: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("informer did not stop")
+	}
+	if err := informer.Run(context.Background()); err != ErrInformerStarted {
+		t.Errorf("expected ErrInformerStarted, got: %v", err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	sim := routerossim.New()
+	if _, err := sim.Add("/interface", map[string]string{"name": "ether1"}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	client := NewClient(sim.Server().Pipe())
+	if err := client.Login("admin", ""); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	cache := NewCache(client)
+	interfaces := cache.Informer("/interface")
+	if cache.Informer("/interface") != interfaces {
+		t.Errorf("expected the informer to be shared")
+	}
+	cache.Start(ctx)
+	// informers created after Start run right away
+	secrets := cache.Informer("/ppp/secret")
+	if err := cache.WaitForSync(ctx); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if !secrets.HasSynced() || len(secrets.List()) != 0 {
+		t.Errorf("expected an empty synced informer, got: %v", secrets.List())
+	}
+	if items := interfaces.List(); len(items) != 1 || items[0].Value("name") != "ether1" {
+		t.Errorf("unexpected interfaces: %v", items)
+	}
+}
@@ -0,0 +1,186 @@
+// Package rosquery evaluates the query words of the RouterOS API, it is shared
+// by the query builder of the client and the simulator.
+package rosquery
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Op the comparison of a query word
+type Op int
+
+const (
+	// Eq ?name=value
+	Eq Op = iota
+	// Lt ?<name=value
+	Lt
+	// Gt ?>name=value
+	Gt
+	// Has ?name
+	Has
+	// Missing ?-name
+	Missing
+)
+
+// Condition is a query word other than ?#
+type Condition struct {
+	Op    Op
+	Name  string
+	Value string
+}
+
+// Match reports whether the value of the attribute, ok when present, meets
+// the condition
+func (c Condition) Match(value string, ok bool) bool {
+	switch c.Op {
+	case Lt:
+		return ok && Compare(value, c.Value) < 0
+	case Gt:
+		return ok && Compare(value, c.Value) > 0
+	case Has:
+		return ok
+	case Missing:
+		return !ok
+	}
+	return ok && value == c.Value
+}
+
+// Compare compares numerically when both values are integers, as strings otherwise
+func Compare(a, b string) int {
+	x, errA := strconv.ParseInt(a, 10, 64)
+	y, errB := strconv.ParseInt(b, 10, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// Algebra builds the values of the stack, e.g. booleans or query nodes
+type Algebra[T any] struct {
+	Cond func(Condition) T
+	Not  func(T) T
+	And  func(a, b T) T
+	Or   func(a, b T) T
+}
+
+// Stack evaluates the words with the RouterOS stack rules: every word pushes a
+// condition and ?# applies operations to the stack (! negates the top, & and |
+// combine the two top values, a number pushes a copy of the value at that
+// index from the top or, at the end of the word, replaces the stack with it,
+// and . pushes a copy of the top unless it follows a number). The values left
+// on the stack are combined with and by the caller.
+func Stack[T any](words []string, a Algebra[T]) ([]T, error) {
+	var stack []T
+	for _, word := range words {
+		if !strings.HasPrefix(word, "?") {
+			return nil, fmt.Errorf("invalid query word %q", word)
+		}
+		w := word[1:]
+		switch {
+		case strings.HasPrefix(w, "#"):
+			var err error
+			if stack, err = operations(stack, w[1:], a); err != nil {
+				return nil, fmt.Errorf("invalid query word %q: %w", word, err)
+			}
+		case strings.HasPrefix(w, "-"):
+			stack = append(stack, a.Cond(Condition{Op: Missing, Name: w[1:]}))
+		case strings.HasPrefix(w, "<"), strings.HasPrefix(w, ">"):
+			name, value, _ := strings.Cut(w[1:], "=")
+			op := Lt
+			if w[0] == '>' {
+				op = Gt
+			}
+			stack = append(stack, a.Cond(Condition{Op: op, Name: name, Value: value}))
+		default:
+			name, value, ok := strings.Cut(strings.TrimPrefix(w, "="), "=")
+			if ok {
+				stack = append(stack, a.Cond(Condition{Op: Eq, Name: name, Value: value}))
+			} else {
+				stack = append(stack, a.Cond(Condition{Op: Has, Name: name}))
+			}
+		}
+	}
+	return stack, nil
+}
+
+// Match evaluates the words against an item whose attributes are read with get
+func Match(words []string, get func(name string) (string, bool)) (bool, error) {
+	stack, err := Stack(words, Algebra[bool]{
+		Cond: func(c Condition) bool { return c.Match(get(c.Name)) },
+		Not:  func(v bool) bool { return !v },
+		And:  func(a, b bool) bool { return a && b },
+		Or:   func(a, b bool) bool { return a || b },
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, v := range stack {
+		if !v {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func operations[T any](stack []T, ops string, a Algebra[T]) ([]T, error) {
+	afterIndex := false
+	for i := 0; i < len(ops); i++ {
+		ch := ops[i]
+		if ch >= '0' && ch <= '9' {
+			j := i
+			for j < len(ops) && ops[j] >= '0' && ops[j] <= '9' {
+				j++
+			}
+			index, _ := strconv.Atoi(ops[i:j])
+			if index >= len(stack) {
+				return nil, fmt.Errorf("index %d out of the stack", index)
+			}
+			value := stack[len(stack)-1-index]
+			if j == len(ops) {
+				return []T{value}, nil
+			}
+			stack = append(stack, value)
+			i = j - 1
+			afterIndex = true
+			continue
+		}
+		switch ch {
+		case '!':
+			if len(stack) < 1 {
+				return nil, errors.New("empty stack")
+			}
+			stack[len(stack)-1] = a.Not(stack[len(stack)-1])
+		case '&', '|':
+			if len(stack) < 2 {
+				return nil, errors.New("not enough values in the stack")
+			}
+			x, y := stack[len(stack)-2], stack[len(stack)-1]
+			stack = stack[:len(stack)-2]
+			if ch == '&' {
+				stack = append(stack, a.And(x, y))
+			} else {
+				stack = append(stack, a.Or(x, y))
+			}
+		case '.':
+			if !afterIndex {
+				if len(stack) < 1 {
+					return nil, errors.New("empty stack")
+				}
+				stack = append(stack, stack[len(stack)-1])
+			}
+		default:
+			return nil, fmt.Errorf("unknown operation %q", ch)
+		}
+		afterIndex = false
+	}
+	return stack, nil
+}
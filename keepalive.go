@@ -0,0 +1,84 @@
+package go_routeros
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrDeadConnection is reported to pending commands when RouterOS did not
+// answer a keepalive in time
+var ErrDeadConnection = errors.New("router os did not answer the keepalive")
+
+// EnableKeepalive sends /system/identity/print every interval. When no reply
+// arrives within timeout the connection is considered dead: pending commands
+// fail with ErrDeadConnection and, if enabled, the client reconnects.
+func (c *Client) EnableKeepalive(interval, timeout time.Duration) {
+	c.lock.Lock()
+	if c.keepaliveCancel != nil {
+		c.keepaliveCancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.keepaliveCancel = cancel
+	c.lock.Unlock()
+
+	go c.keepalive(ctx, interval, timeout)
+}
+
+// DisableKeepalive stops the keepalive
+func (c *Client) DisableKeepalive() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.keepaliveCancel != nil {
+		c.keepaliveCancel()
+		c.keepaliveCancel = nil
+	}
+}
+
+func (c *Client) keepalive(ctx context.Context, interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !c.IsConnected() {
+			continue
+		}
+
+		runCtx, cancel := context.WithTimeout(ctx, timeout)
+		_, err := c.Run(runCtx, "/system/identity/print")
+		cancel()
+		// a !trap is still an answer, only a missing reply means a dead peer
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			if logger := c.log(); logger != nil {
+				logger.Warn("keepalive timed out", "timeout", timeout)
+			}
+			c.markDead(ErrDeadConnection)
+		}
+	}
+}
+
+// markDead closes the connection so the read loop fails with cause
+func (c *Client) markDead(cause error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.deadCause == nil {
+		c.deadCause = cause
+	}
+	if c.conn != nil {
+		_ = c.conn.Close()
+	}
+}
+
+// connectionError returns why the connection was lost, err is the read error
+func (c *Client) connectionError(err error) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.deadCause != nil {
+		err, c.deadCause = c.deadCause, nil
+	}
+	return err
+}
@@ -0,0 +1,56 @@
+package go_routeros
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/leandrose/go-routeros/routerostest"
+)
+
+func TestKeepaliveDetectsDeadConnection(t *testing.T) {
+	server := routerostest.NewServer()
+	server.Reply("/interface/listen", routerostest.Block())
+	client := newTestClient(t, server)
+	// the router stops answering after the login
+	server.Reply("/system/identity/print", routerostest.Block())
+
+	req, err := client.SendCommandContext(context.Background(), "/interface/listen")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	client.EnableKeepalive(10*time.Millisecond, 30*time.Millisecond)
+
+	var last Response
+	for response := range req.Responses() {
+		last = response
+	}
+	if !errors.Is(last.Err, ErrDeadConnection) {
+		t.Errorf("expected ErrDeadConnection, got: %v", last.Err)
+	}
+}
+
+func TestKeepaliveAnsweredConnection(t *testing.T) {
+	server := routerostest.NewServer()
+	server.Reply("/interface/listen", routerostest.Block())
+	server.Reply("/system/identity/print", routerostest.Re("=name=router"))
+	client := newTestClient(t, server)
+
+	req, err := client.SendCommandContext(context.Background(), "/interface/listen")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	client.EnableKeepalive(10*time.Millisecond, 30*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	client.DisableKeepalive()
+
+	select {
+	case response := <-req.Responses():
+		t.Fatalf("expected the listen to keep running, got: %s %v", response.Type, response.Err)
+	default:
+	}
+	if !client.IsConnected() {
+		t.Errorf("expected the client to be connected")
+	}
+}
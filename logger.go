@@ -0,0 +1,119 @@
+package go_routeros
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+)
+
+// DefaultRedactedAttributes attributes whose values are never logged
+var DefaultRedactedAttributes = []string{"password", "response", "secret", "private-key"}
+
+const redacted = "<redacted>"
+
+var debugLogger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+// SetLogger sets the logger used by the client. Connection events are logged
+// at info and warn level, the words sent and received at debug level, so they
+// are logged when the level of the logger includes debug.
+func (c *Client) SetLogger(logger *slog.Logger) {
+	c.logLock.Lock()
+	defer c.logLock.Unlock()
+	c.logger = logger
+}
+
+// SetRedactedAttributes replaces the attributes whose values are replaced by
+// <redacted> in the logs, DefaultRedactedAttributes is used by default
+func (c *Client) SetRedactedAttributes(names ...string) {
+	c.logLock.Lock()
+	defer c.logLock.Unlock()
+	c.redacted = names
+	c.redactSet = true
+}
+
+// EnableDebug writes the words sent and received to stdout when no logger was
+// set with SetLogger. A logger set logs them according to its level.
+func (c *Client) EnableDebug() {
+	c.logLock.Lock()
+	defer c.logLock.Unlock()
+	c.debug = true
+}
+
+// DisableDebug stops writing the words sent and received to stdout
+func (c *Client) DisableDebug() {
+	c.logLock.Lock()
+	defer c.logLock.Unlock()
+	c.debug = false
+}
+
+// log returns the logger with the connection attribute, or nil when logging is disabled
+func (c *Client) log() *slog.Logger {
+	c.logLock.RLock()
+	logger, debug, remote := c.logger, c.debug, c.remote
+	c.logLock.RUnlock()
+	if logger == nil {
+		if !debug {
+			return nil
+		}
+		logger = debugLogger
+	}
+	if remote != "" {
+		logger = logger.With("conn", remote)
+	}
+	return logger
+}
+
+// logWords logs a sentence sent or received at debug level
+func (c *Client) logWords(msg string, words []string) {
+	logger := c.log()
+	if logger == nil || !logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	words = c.redact(words)
+	attrs := []any{"words", words}
+	for _, word := range words {
+		if strings.HasPrefix(word, ".tag=") {
+			attrs = append(attrs, "tag", strings.TrimPrefix(word, ".tag="))
+		}
+	}
+	logger.Debug(msg, attrs...)
+}
+
+// redact returns a copy of words with the values of sensitive attributes hidden
+func (c *Client) redact(words []string) []string {
+	c.logLock.RLock()
+	names := c.redacted
+	if !c.redactSet {
+		names = DefaultRedactedAttributes
+	}
+	c.logLock.RUnlock()
+
+	out := make([]string, len(words))
+	for i, word := range words {
+		out[i] = word
+		if !strings.HasPrefix(word, "=") {
+			continue
+		}
+		key, _, ok := strings.Cut(word[1:], "=")
+		if !ok {
+			continue
+		}
+		for _, name := range names {
+			if key == name {
+				out[i] = "=" + key + "=" + redacted
+				break
+			}
+		}
+	}
+	return out
+}
+
+// remoteAddr returns the address of conn when it is a network connection
+func remoteAddr(conn interface{}) string {
+	if nc, ok := conn.(net.Conn); ok && nc.RemoteAddr() != nil {
+		return nc.RemoteAddr().String()
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+package go_routeros
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogRedactsSensitiveWords(t *testing.T) {
+	buf := &bytes.Buffer{}
+	c := &Client{conn: discardConn{}}
+	c.SetLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	if err := c.writeSentence([]string{"/login", "=name=admin", "=password=s3cr3t", ".tag=7"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "s3cr3t") {
+		t.Errorf("password leaked to the log: %s", out)
+	}
+	if !strings.Contains(out, "=password=<redacted>") || !strings.Contains(out, "=name=admin") {
+		t.Errorf("expected the redacted sentence, got: %s", out)
+	}
+	if !strings.Contains(out, `"tag":"7"`) {
+		t.Errorf("expected the tag attribute, got: %s", out)
+	}
+
+	buf.Reset()
+	c.SetLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	c.EnableDebug()
+	_ = c.writeSentence([]string{"/system/identity/print"})
+	if buf.Len() != 0 {
+		t.Errorf("expected no wire log above the debug level, got: %s", buf.String())
+	}
+}
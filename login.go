@@ -0,0 +1,270 @@
+package go_routeros
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidCredentials the router rejected the user name or password
+	ErrInvalidCredentials = errors.New("invalid user name or password")
+	// ErrLoginTimeout the login did not finish before the deadline
+	ErrLoginTimeout = errors.New("login timeout")
+	// ErrTooManyLoginAttempts the router refuses logins after repeated failures
+	ErrTooManyLoginAttempts = errors.New("too many login attempts")
+)
+
+// maxLoginReplies bounds the replies read by a login, a router answers with
+// one sentence or two after a failure
+const maxLoginReplies = 4
+
+// LoginMode the authentication exchange used by Login
+type LoginMode int
+
+const (
+	// LoginAuto sends the name and password and answers the MD5 challenge of
+	// routers older than 6.43 when it is requested
+	LoginAuto LoginMode = iota
+	// LoginPlain sends the name and password, RouterOS 6.43 and newer
+	LoginPlain
+	// LoginChallenge requests the MD5 challenge first, RouterOS before 6.43
+	LoginChallenge
+)
+
+func (m LoginMode) String() string {
+	switch m {
+	case LoginAuto:
+		return "auto"
+	case LoginPlain:
+		return "plain"
+	case LoginChallenge:
+		return "challenge"
+	}
+	return "unknown"
+}
+
+// WithLoginMode sets the authentication exchange, the default is LoginAuto
+func WithLoginMode(mode LoginMode) Option {
+	return func(cfg *config) {
+		cfg.loginMode = mode
+	}
+}
+
+// RouterInfo describes the router a client is logged in to
+type RouterInfo struct {
+	// Identity the name of /system/identity
+	Identity string
+	// Version e.g. 7.12.1 (stable)
+	Version      string
+	BoardName    string
+	Architecture string
+}
+
+// AtLeast reports whether the version is major.minor or newer, it is false
+// when the version is unknown
+func (i RouterInfo) AtLeast(major, minor int) bool {
+	number, _, _ := strings.Cut(i.Version, " ")
+	parts := strings.Split(number, ".")
+	if len(parts) < 2 {
+		return false
+	}
+	// the minor of a release candidate is followed by rc or beta, e.g. 7.15rc2
+	minorDigits := parts[1]
+	if i := strings.IndexFunc(minorDigits, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		minorDigits = minorDigits[:i]
+	}
+	x, err1 := strconv.Atoi(parts[0])
+	y, err2 := strconv.Atoi(minorDigits)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return x > major || x == major && y >= minor
+}
+
+// Login to routeros, bounded by the login timeout of the client
+func (c *Client) Login(username, password string) error {
+	return c.LoginContext(context.Background(), username, password)
+}
+
+// LoginContext logs in with the login mode of the client, the exchange is
+// abandoned with ErrLoginTimeout when ctx is done. Interrupting the exchange
+// needs a connection with deadlines, such as a net.Conn. The identity and
+// version of the router are read afterwards within the same deadline, see
+// RouterInfo.
+func (c *Client) LoginContext(ctx context.Context, username, password string) error {
+	ctx, cancel := c.withLoginTimeout(ctx)
+	defer cancel()
+	if err := c.login(ctx, username, password); err != nil {
+		return err
+	}
+	c.lock.Lock()
+	c.username, c.password = username, password
+	c.isConnected = true
+	c.lock.Unlock()
+	c.startReadLoop()
+	c.readRouterInfo(ctx)
+	return nil
+}
+
+// RouterInfo returns what the router reported after the login, the fields
+// the user is not allowed to read, or not read before the login timeout, are
+// empty
+func (c *Client) RouterInfo() RouterInfo {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.info
+}
+
+func (c *Client) readRouterInfo(ctx context.Context) {
+	var info RouterInfo
+	if reply, err := c.Run(ctx, "/system/identity/print"); err == nil && len(reply.Re) > 0 {
+		info.Identity = reply.Re[0].Value("name")
+	} else if logger := c.log(); logger != nil && err != nil {
+		logger.Debug("could not read the identity", "error", err)
+	}
+	if reply, err := c.Run(ctx, "/system/resource/print"); err == nil && len(reply.Re) > 0 {
+		info.Version = reply.Re[0].Value("version")
+		info.BoardName = reply.Re[0].Value("board-name")
+		info.Architecture = reply.Re[0].Value("architecture-name")
+	} else if logger := c.log(); logger != nil && err != nil {
+		logger.Debug("could not read the version", "error", err)
+	}
+	c.lock.Lock()
+	c.info = info
+	c.lock.Unlock()
+}
+
+// withLoginTimeout bounds ctx by the login timeout of the client
+func (c *Client) withLoginTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.loginTimeout > 0 {
+		return context.WithTimeout(ctx, c.loginTimeout)
+	}
+	return ctx, func() {}
+}
+
+// login authenticates the current connection, the read loop must not be
+// running. ctx carries the login timeout, see withLoginTimeout.
+func (c *Client) login(ctx context.Context, username, password string) error {
+	if d, ok := c.conn.(interface{ SetDeadline(time.Time) error }); ok {
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = d.SetDeadline(deadline)
+		}
+		defer d.SetDeadline(time.Time{})
+		// a done context unblocks the exchange at once
+		stop := context.AfterFunc(ctx, func() {
+			_ = d.SetDeadline(time.Now())
+		})
+		defer stop()
+	}
+
+	err := c.loginExchange(username, password)
+	if err != nil && (ctx.Err() != nil || errors.Is(err, os.ErrDeadlineExceeded)) {
+		return fmt.Errorf("%w: %w", ErrLoginTimeout, err)
+	}
+	return err
+}
+
+func (c *Client) loginExchange(username, password string) error {
+	if c.loginMode == LoginChallenge {
+		done, err := c.loginRequest([]string{"/login"})
+		if err != nil {
+			return err
+		}
+		ret, ok := done.Get("ret")
+		if !ok {
+			return errors.New("login: the router sent no challenge")
+		}
+		return c.loginChallenge(username, password, ret)
+	}
+
+	done, err := c.loginRequest([]string{
+		"/login",
+		fmt.Sprintf("=name=%s", username),
+		fmt.Sprintf("=password=%s", password),
+	})
+	if err != nil {
+		return err
+	}
+	if ret, ok := done.Get("ret"); ok {
+		if c.loginMode == LoginPlain {
+			return errors.New("login: the router requires the challenge login of RouterOS before 6.43")
+		}
+		return c.loginChallenge(username, password, ret)
+	}
+	return nil
+}
+
+func (c *Client) loginChallenge(username, password, ret string) error {
+	challenge, err := hex.DecodeString(ret)
+	if err != nil {
+		return fmt.Errorf("login: invalid challenge: %w", err)
+	}
+	_, err = c.loginRequest([]string{
+		"/login",
+		fmt.Sprintf("=name=%s", username),
+		fmt.Sprintf("=response=%s", c.challengeResponse(challenge, password)),
+	})
+	return err
+}
+
+// loginRequest sends a /login sentence and returns its !done, a failure is
+// returned as a typed error once its !done was read
+func (c *Client) loginRequest(words []string) (Sentence, error) {
+	if err := c.writeSentence(words); err != nil {
+		return Sentence{}, err
+	}
+	var failure error
+	for i := 0; i < maxLoginReplies; i++ {
+		sentence, err := c.readSentence()
+		if err != nil {
+			if failure != nil {
+				return Sentence{}, failure
+			}
+			return Sentence{}, err
+		}
+		switch sentence.Word {
+		case "!done":
+			if failure != nil {
+				return Sentence{}, failure
+			}
+			return sentence, nil
+		case "!trap":
+			failure = loginError(newRouterOSError(sentence, []string{"/login"}))
+		case "!fatal":
+			return Sentence{}, loginError(newRouterOSError(sentence, []string{"/login"}))
+		default:
+			return Sentence{}, fmt.Errorf("login: unexpected reply %s", sentence.Word)
+		}
+	}
+	return Sentence{}, errors.New("login: too many replies")
+}
+
+// loginError classifies a failed login, the RouterOSError stays reachable
+// with errors.As
+func loginError(err *RouterOSError) error {
+	message := strings.ToLower(err.Message)
+	switch {
+	case strings.Contains(message, "too many"):
+		return fmt.Errorf("%w: %w", ErrTooManyLoginAttempts, err)
+	case strings.Contains(message, "invalid user name or password"),
+		strings.Contains(message, "cannot log in"):
+		return fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+	return err
+}
+
+// challengeResponse - prepare MD5 hash for auth challenge response
+func (c *Client) challengeResponse(cha []byte, password string) string {
+	h := md5.New() //nolint:gosec
+	h.Write([]byte{0})
+	h.Write([]byte(password))
+	h.Write(cha)
+	return fmt.Sprintf("00%x", h.Sum(nil))
+}
@@ -0,0 +1,173 @@
+package go_routeros
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/leandrose/go-routeros/routerostest"
+)
+
+func TestLoginModes(t *testing.T) {
+	tests := []struct {
+		name  string
+		style routerostest.LoginStyle
+		mode  LoginMode
+		fail  bool
+	}{
+		{"Automático com servidor novo", routerostest.LoginPlain, LoginAuto, false},
+		{"Automático com servidor antigo", routerostest.LoginChallenge, LoginAuto, false},
+		{"Simples com servidor novo", routerostest.LoginPlain, LoginPlain, false},
+		{"Simples com servidor antigo", routerostest.LoginChallenge, LoginPlain, true},
+		{"Desafio com servidor antigo", routerostest.LoginChallenge, LoginChallenge, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := routerostest.NewServer()
+			server.Password = "secret"
+			server.LoginStyle = tt.style
+			client := NewClient(server.Pipe(), WithLoginMode(tt.mode))
+			defer client.Close()
+			err := client.Login("admin", "secret")
+			if tt.fail != (err != nil) {
+				t.Fatalf("unexpected login result: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoginErrors(t *testing.T) {
+	t.Run("Credenciais inválidas", func(t *testing.T) {
+		server := routerostest.NewServer()
+		server.Password = "secret"
+		client := NewClient(server.Pipe())
+		defer client.Close()
+		err := client.Login("admin", "wrong")
+		var rosErr *RouterOSError
+		if !errors.Is(err, ErrInvalidCredentials) || !errors.As(err, &rosErr) {
+			t.Fatalf("expected ErrInvalidCredentials, got: %v", err)
+		}
+		// the !done of the failure was read, the connection can log in again
+		if err := client.Login("admin", "secret"); err != nil {
+			t.Errorf("expected the second login to succeed, got: %v", err)
+		}
+	})
+
+	t.Run("Muitas tentativas", func(t *testing.T) {
+		err := loginError(newRouterOSError(parseSentence([]string{"!trap", "=message=too many login attempts"}), nil))
+		if !errors.Is(err, ErrTooManyLoginAttempts) {
+			t.Errorf("expected ErrTooManyLoginAttempts, got: %v", err)
+		}
+	})
+
+	t.Run("Prazo do contexto", func(t *testing.T) {
+		conn, peer := net.Pipe()
+		defer peer.Close()
+		go discardAll(peer)
+		client := NewClient(conn)
+		defer client.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if err := client.LoginContext(ctx, "admin", ""); !errors.Is(err, ErrLoginTimeout) {
+			t.Errorf("expected ErrLoginTimeout, got: %v", err)
+		}
+	})
+
+	t.Run("Contexto cancelado", func(t *testing.T) {
+		conn, peer := net.Pipe()
+		defer peer.Close()
+		go discardAll(peer)
+		client := NewClient(conn)
+		defer client.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		if err := client.LoginContext(ctx, "admin", ""); !errors.Is(err, ErrLoginTimeout) {
+			t.Errorf("expected ErrLoginTimeout, got: %v", err)
+		}
+	})
+
+	t.Run("Resposta inesperada", func(t *testing.T) {
+		conn, peer := net.Pipe()
+		defer peer.Close()
+		go func() {
+			buf := make([]byte, 1024)
+			if _, err := peer.Read(buf); err != nil {
+				return
+			}
+			_, _ = peer.Write(encodeTestSentence("!re", "=name=x"))
+			discardAll(peer)
+		}()
+		client := NewClient(conn)
+		defer client.Close()
+		if err := client.Login("admin", ""); err == nil {
+			t.Errorf("expected an error for an unexpected reply")
+		}
+	})
+}
+
+func TestRouterInfo(t *testing.T) {
+	server := routerostest.NewServer()
+	server.Reply("/system/identity/print", routerostest.Re("=name=core-1"))
+	server.Reply("/system/resource/print", routerostest.Re("=version=7.12.1 (stable)", "=board-name=CCR2004", "=architecture-name=arm64"))
+	client := newTestClient(t, server)
+	info := client.RouterInfo()
+	expected := RouterInfo{Identity: "core-1", Version: "7.12.1 (stable)", BoardName: "CCR2004", Architecture: "arm64"}
+	if info != expected {
+		t.Fatalf("expected: %+v, got: %+v", expected, info)
+	}
+
+	t.Run("Prazo do login", func(t *testing.T) {
+		server := routerostest.NewServer()
+		server.Reply("/system/identity/print", routerostest.Block())
+		client := NewClient(server.Pipe(), WithLoginTimeout(100*time.Millisecond))
+		defer client.Close()
+		start := time.Now()
+		if err := client.Login("admin", ""); err != nil {
+			t.Fatalf("login: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("expected the login to end with its timeout, took: %s", elapsed)
+		}
+		if info := client.RouterInfo(); info.Identity != "" {
+			t.Errorf("expected no identity, got: %+v", info)
+		}
+	})
+
+	versions := []struct {
+		version      string
+		major, minor int
+		expected     bool
+	}{
+		{"7.12.1 (stable)", 7, 10, true},
+		{"7.12.1 (stable)", 7, 13, false},
+		{"6.49.10 (long-term)", 6, 43, true},
+		{"6.42 (bugfix)", 6, 43, false},
+		{"7.15rc2 (testing)", 7, 15, true},
+		{"", 6, 0, false},
+	}
+	for _, v := range versions {
+		if got := (RouterInfo{Version: v.version}).AtLeast(v.major, v.minor); got != v.expected {
+			t.Errorf("%q at least %d.%d: expected: %v, got: %v", v.version, v.major, v.minor, v.expected, got)
+		}
+	}
+}
+
+func discardAll(conn net.Conn) {
+	buf := make([]byte, 1024)
+	for {
+		if _, err := conn.Read(buf); err != nil {
+			return
+		}
+	}
+}
+
+func encodeTestSentence(words ...string) []byte {
+	var out []byte
+	for _, word := range words {
+		out = append(out, encodeLength(len(word))...)
+		out = append(out, word...)
+	}
+	return append(out, 0)
+}
@@ -0,0 +1,250 @@
+package go_routeros
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leandrose/go-routeros/rostypes"
+)
+
+// LogEntry is an entry of the RouterOS log
+type LogEntry struct {
+	// ID the .id of the entry, it grows with every entry until the router reboots
+	ID string
+	// Time when the entry was logged, in the location of LogOptions
+	Time time.Time
+	// Topics e.g. system, info, account
+	Topics  []string
+	Message string
+	// Sentence the reply the entry was parsed from
+	Sentence Sentence
+}
+
+// HasTopic reports whether the entry has the topic
+func (e LogEntry) HasTopic(topic string) bool {
+	for _, t := range e.Topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// LogOptions options of a LogStream
+type LogOptions struct {
+	// Topics keeps only the entries with at least one of the topics
+	Topics []string
+	// Exclude skips the entries with any of the topics
+	Exclude []string
+	// Query is sent to the router with the print. API queries compare whole
+	// values, so Topics and Exclude are applied locally.
+	Query Query
+	// FollowOnly skips the entries logged before the stream started
+	FollowOnly bool
+	// Location of the router clock, time.Local when nil
+	Location *time.Location
+}
+
+// LogStream follows the log of the router with /log/print. After a reconnect
+// the log is printed again and the entries up to the last one seen are skipped,
+// so no entry is lost or repeated unless the router dropped it from memory.
+type LogStream struct {
+	req     *Request
+	opts    LogOptions
+	entries chan LogEntry
+
+	// since with FollowOnly, the entries printed again after a reconnect are
+	// skipped up to this time when none was seen before
+	since time.Time
+
+	mu       sync.Mutex
+	lastID   uint64
+	lastTime time.Time
+	seen     bool
+	resumed  bool
+	err      error
+	done     chan struct{}
+}
+
+// FollowLog starts a LogStream, it stops when ctx is done or the print fails
+func (c *Client) FollowLog(ctx context.Context, opts LogOptions) (*LogStream, error) {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	s := &LogStream{
+		opts:    opts,
+		entries: make(chan LogEntry),
+		done:    make(chan struct{}),
+	}
+	follow := "=follow=yes"
+	if opts.FollowOnly {
+		follow = "=follow-only=yes"
+		s.since = time.Now().Truncate(time.Second)
+	}
+	args := append([]string{follow}, opts.Query.Words()...)
+	req, err := c.SendCommandWithOptions(ctx, CommandOptions{
+		Resubscribe: true,
+		// the whole log is printed again, the entries already seen are skipped
+		Resume: func() []string {
+			return append([]string{"=follow=yes"}, opts.Query.Words()...)
+		},
+	}, "/log/print", args...)
+	if err != nil {
+		return nil, err
+	}
+	s.req = req
+	go s.run(ctx)
+	return s, nil
+}
+
+// Entries returns the channel of log entries, it is closed when the stream stops
+func (s *LogStream) Entries() <-chan LogEntry {
+	return s.entries
+}
+
+// Done returns a channel that is closed when the stream stops
+func (s *LogStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the stream stopped, or nil while it is running
+func (s *LogStream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// LastID returns the .id of the last entry received, including filtered ones
+func (s *LogStream) LastID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.seen {
+		return ""
+	}
+	return formatID(s.lastID)
+}
+
+func (s *LogStream) run(ctx context.Context) {
+	err := s.follow(ctx)
+	_ = s.req.Cancel()
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	close(s.entries)
+	close(s.done)
+}
+
+func (s *LogStream) follow(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case response, ok := <-s.req.Responses():
+			if !ok {
+				if err := s.req.Err(); err != nil {
+					return err
+				}
+				return ErrClosed
+			}
+			switch response.Type {
+			case "!re":
+				entry := ParseLogEntry(response.Sentence, time.Now(), s.opts.Location)
+				if !s.next(entry) || !s.keep(entry) {
+					continue
+				}
+				select {
+				case s.entries <- entry:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			case TypeResynced:
+				s.mu.Lock()
+				s.resumed = true
+				s.mu.Unlock()
+			case "!trap", "!fatal":
+				return response.Err
+			case "!done", "!empty":
+				return ErrClosed
+			}
+		}
+	}
+}
+
+// next records the entry as the last one seen, it returns false for an entry
+// printed again after a reconnect. The .id restarts when the router reboots,
+// a lower .id logged later than the last entry is therefore new.
+func (s *LogStream) next(entry LogEntry) bool {
+	id, err := ID(entry.ID).Number()
+	if err != nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen && id <= s.lastID && !entry.Time.After(s.lastTime) {
+		return false
+	}
+	if !s.seen && s.resumed && entry.Time.Before(s.since) {
+		return false
+	}
+	s.lastID, s.lastTime, s.seen = id, entry.Time, true
+	return true
+}
+
+// keep applies the topic filters
+func (s *LogStream) keep(entry LogEntry) bool {
+	for _, topic := range s.opts.Exclude {
+		if entry.HasTopic(topic) {
+			return false
+		}
+	}
+	if len(s.opts.Topics) == 0 {
+		return true
+	}
+	for _, topic := range s.opts.Topics {
+		if entry.HasTopic(topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLogEntry parses a reply of /log/print. The time may use any of the v6
+// or v7 formats, the short ones are completed from now. A date without the
+// year more than a day ahead of now was logged the year before, e.g. dec/31
+// read on jan/01. An invalid time is left as the zero time.
+func ParseLogEntry(s Sentence, now time.Time, loc *time.Location) LogEntry {
+	entry := LogEntry{
+		ID:       s.Value(".id"),
+		Message:  s.Value("message"),
+		Sentence: s,
+	}
+	if topics := s.Value("topics"); topics != "" {
+		entry.Topics = strings.Split(topics, ",")
+	}
+	value := s.Value("time")
+	if t, err := rostypes.ParseTime(value, now, loc); err == nil {
+		if !hasYear(value) && t.Sub(now) > 24*time.Hour {
+			t = t.AddDate(-1, 0, 0)
+		}
+		entry.Time = t
+	}
+	return entry
+}
+
+// hasYear reports whether a log time includes the year, e.g. jan/02/2006
+// 15:04:05 but not jan/02 15:04:05 or 15:04:05
+func hasYear(value string) bool {
+	date, _, ok := strings.Cut(strings.TrimSpace(value), " ")
+	if !ok {
+		return false
+	}
+	return strings.Count(date, "/") == 2 || strings.Count(date, "-") == 2
+}
+
+// formatID formats the number of an .id, e.g. *1A
+func formatID(n uint64) string {
+	return "*" + strings.ToUpper(strconv.FormatUint(n, 16))
+}
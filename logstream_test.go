@@ -0,0 +1,209 @@
+package go_routeros
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leandrose/go-routeros/routerostest"
+)
+
+func TestParseLogEntry(t *testing.T) {
+	now := time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		time string
+		want time.Time
+	}{
+		{"Formato v6 somente hora", "10:00:01", time.Date(2024, time.March, 5, 10, 0, 1, 0, time.UTC)},
+		{"Formato v6 sem ano", "mar/01 08:00:00", time.Date(2024, time.March, 1, 8, 0, 0, 0, time.UTC)},
+		{"Formato v6 sem ano do ano anterior", "dec/31 23:59:59", time.Date(2023, time.December, 31, 23, 59, 59, 0, time.UTC)},
+		{"Formato v6 completo", "dec/31/2023 23:59:59", time.Date(2023, time.December, 31, 23, 59, 59, 0, time.UTC)},
+		{"Formato v7", "2024-03-04 07:30:00", time.Date(2024, time.March, 4, 7, 30, 0, 0, time.UTC)},
+		{"Formato v7 sem ano", "03-04 07:30:00", time.Date(2024, time.March, 4, 7, 30, 0, 0, time.UTC)},
+		{"Inválido", "ontem", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := parseSentence([]string{"!re", "=.id=*1A", "=time=" + tt.time, "=topics=system,info,account", "=message=user admin logged in"})
+			entry := ParseLogEntry(s, now, time.UTC)
+			if !entry.Time.Equal(tt.want) {
+				t.Errorf("expected time: %s, got: %s", tt.want, entry.Time)
+			}
+			if entry.ID != "*1A" || entry.Message != "user admin logged in" || !reflect.DeepEqual(entry.Topics, []string{"system", "info", "account"}) {
+				t.Errorf("unexpected entry: %+v", entry)
+			}
+		})
+	}
+}
+
+func TestLogStreamNext(t *testing.T) {
+	dec31 := time.Date(2024, time.December, 31, 23, 59, 59, 0, time.UTC)
+	jan1 := time.Date(2025, time.January, 1, 0, 0, 5, 0, time.UTC)
+	tests := []struct {
+		name     string
+		last     []string
+		entry    []string
+		now      time.Time
+		expected bool
+	}{
+		{"Entrada nova", []string{"*A", "2024-12-31 23:59:59"}, []string{"*B", "2025-01-01 00:00:01"}, jan1, true},
+		{"Entrada repetida", []string{"*A", "2024-12-31 23:59:59"}, []string{"*A", "2024-12-31 23:59:59"}, jan1, false},
+		{"Reinício do roteador", []string{"*A", "2024-12-31 23:59:59"}, []string{"*1", "2025-01-01 00:00:01"}, jan1, true},
+		{"Reinício do roteador sem ano", []string{"*A", "23:50:00"}, []string{"*1", "23:59:30"}, dec31, true},
+		{"Entrada repetida sem ano", []string{"*A", "23:50:00"}, []string{"*9", "23:49:00"}, dec31, false},
+		{"Virada de ano sem ano", []string{"*A", "dec/31 23:59:59"}, []string{"*A", "dec/31 23:59:59"}, jan1, false},
+		{"Virada de ano sem ano anterior", []string{"*A", "dec/31 23:59:59"}, []string{"*9", "dec/31 23:59:58"}, jan1, false},
+		{"Virada de ano sem ano nova", []string{"*A", "dec/31 23:59:59"}, []string{"*B", "jan/01 00:00:01"}, jan1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &LogStream{}
+			last := parseSentence([]string{"!re", "=.id=" + tt.last[0], "=time=" + tt.last[1]})
+			if !s.next(ParseLogEntry(last, dec31, time.UTC)) {
+				t.Fatalf("expected the first entry to be new")
+			}
+			entry := parseSentence([]string{"!re", "=.id=" + tt.entry[0], "=time=" + tt.entry[1]})
+			if got := s.next(ParseLogEntry(entry, tt.now, time.UTC)); got != tt.expected {
+				t.Errorf("expected: %v, got: %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// logServer serves /log/print with the entries of log, then blocks like follow
+type logServer struct {
+	mu  sync.Mutex
+	log [][]string
+}
+
+func (l *logServer) append(words ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.log = append(l.log, words)
+}
+
+func (l *logServer) ServeRouterOS(ctx context.Context, w *routerostest.ResponseWriter, cmd *routerostest.Command) {
+	if _, ok := cmd.Attr("follow-only"); !ok {
+		l.mu.Lock()
+		log := append([][]string(nil), l.log...)
+		l.mu.Unlock()
+		for _, words := range log {
+			_ = w.Re(words...)
+		}
+	}
+	w.Detach()
+	<-ctx.Done()
+}
+
+func nextLogEntry(t *testing.T, s *LogStream) LogEntry {
+	t.Helper()
+	select {
+	case entry, ok := <-s.Entries():
+		if !ok {
+			t.Fatalf("log stream stopped: %v", s.Err())
+		}
+		return entry
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout waiting for a log entry")
+	}
+	return LogEntry{}
+}
+
+func TestFollowLogResumesAfterReconnect(t *testing.T) {
+	logs := &logServer{}
+	logs.append("=.id=*1", "=time=2024-03-05 10:00:00", "=topics=system,info", "=message=router rebooted")
+	logs.append("=.id=*2", "=time=2024-03-05 10:00:05", "=topics=dhcp,debug", "=message=lease offered")
+	logs.append("=.id=*3", "=time=2024-03-05 10:00:10", "=topics=system,info,account", "=message=user admin logged in")
+
+	server := routerostest.NewServer()
+	server.Handle("/log/print", logs)
+	addr, err := server.Listen()
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer server.Close()
+
+	client, err := DialContext(context.Background(), addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+	client.EnableReconnect(Backoff{Min: 10 * time.Millisecond, Max: 50 * time.Millisecond})
+	if err := client.Login("admin", ""); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.FollowLog(ctx, LogOptions{Exclude: []string{"debug"}, Location: time.UTC})
+	if err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+	for _, id := range []string{"*1", "*3"} {
+		if entry := nextLogEntry(t, stream); entry.ID != id {
+			t.Fatalf("expected %s, got: %+v", id, entry)
+		}
+	}
+
+	logs.append("=.id=*4", "=time=2024-03-05 10:01:00", "=topics=system,error", "=message=link down")
+	server.CloseConnections()
+
+	// the log is printed again, only the new entry is delivered
+	if entry := nextLogEntry(t, stream); entry.ID != "*4" || entry.Message != "link down" {
+		t.Fatalf("expected *4, got: %+v", entry)
+	}
+	if stream.LastID() != "*4" {
+		t.Errorf("expected last id *4, got: %s", stream.LastID())
+	}
+	var prints []routerostest.Command
+	for _, cmd := range server.Received() {
+		if cmd.Path == "/log/print" {
+			prints = append(prints, cmd)
+		}
+	}
+	if last := prints[len(prints)-1]; len(prints) != 2 || !reflect.DeepEqual(last.Words, []string{"=follow=yes"}) {
+		t.Errorf("unexpected resumed command: %+v", prints)
+	}
+
+	cancel()
+	select {
+	case <-stream.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatalf("log stream did not stop")
+	}
+}
+
+func TestFollowLogTopics(t *testing.T) {
+	logs := &logServer{}
+	logs.append("=.id=*1", "=time=10:00:00", "=topics=system,info", "=message=a")
+	logs.append("=.id=*2", "=time=10:00:01", "=topics=firewall,info", "=message=b")
+	logs.append("=.id=*3", "=time=10:00:02", "=topics=system,error", "=message=c")
+	server := routerostest.NewServer()
+	server.Handle("/log/print", logs)
+	client := NewClient(server.Pipe())
+	if err := client.Login("admin", ""); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.FollowLog(ctx, LogOptions{Topics: []string{"system"}, Exclude: []string{"error"}})
+	if err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+	if entry := nextLogEntry(t, stream); entry.Message != "a" {
+		t.Fatalf("expected only the system entry, got: %+v", entry)
+	}
+	// the filtered entries still count as seen
+	deadline := time.Now().Add(2 * time.Second)
+	for stream.LastID() != "*3" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if stream.LastID() != "*3" {
+		t.Errorf("expected last id *3, got: %s", stream.LastID())
+	}
+}
@@ -0,0 +1,200 @@
+package go_routeros
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leandrose/go-routeros/rostypes"
+)
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// Changes are the attribute words of a set and the attributes to unset
+type Changes struct {
+	// Set =name=value words for set
+	Set []string
+	// Unset attribute names for unset =value-name=
+	Unset []string
+}
+
+// IsEmpty reports whether there is nothing to change
+func (c *Changes) IsEmpty() bool {
+	return len(c.Set) == 0 && len(c.Unset) == 0
+}
+
+// Marshal returns the =name=value words of the struct v for add or set, using
+// the routeros:"name" tag of each field. The .id and fields tagged readonly
+// are skipped, as are nil pointers and zero values tagged omitempty; a
+// pointer to an empty value is sent as an empty attribute. Booleans are
+// formatted as yes/no, durations as 1d2h3m4s and slices as comma lists.
+func Marshal(v interface{}) ([]string, error) {
+	rv, fields, err := marshalFields(v)
+	if err != nil {
+		return nil, err
+	}
+	var words []string
+	for _, f := range fields {
+		value, ok, err := fieldValue(rv, f)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			words = append(words, "="+f.name+"="+value)
+		}
+	}
+	return words, nil
+}
+
+// Diff compares two values of the same struct type and returns only the
+// attributes that changed. An attribute present in old and absent in new, a
+// nil pointer or an omitempty zero value, is returned in Unset.
+func Diff(old, new interface{}) (*Changes, error) {
+	oldValue, fields, err := marshalFields(old)
+	if err != nil {
+		return nil, err
+	}
+	newValue, _, err := marshalFields(new)
+	if err != nil {
+		return nil, err
+	}
+	if oldValue.Type() != newValue.Type() {
+		return nil, fmt.Errorf("routeros: Diff of %s and %s", oldValue.Type(), newValue.Type())
+	}
+
+	changes := &Changes{}
+	for _, f := range fields {
+		before, hadBefore, err := fieldValue(oldValue, f)
+		if err != nil {
+			return nil, err
+		}
+		after, hasAfter, err := fieldValue(newValue, f)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case hasAfter && (!hadBefore || before != after):
+			changes.Set = append(changes.Set, "="+f.name+"="+after)
+		case !hasAfter && hadBefore:
+			changes.Unset = append(changes.Unset, f.name)
+		}
+	}
+	return changes, nil
+}
+
+// Update applies the changes between old and new to the item id of the menu,
+// e.g. /ip/address, with one set and one unset per removed attribute
+func (c *Client) Update(ctx context.Context, menu string, id string, old, new interface{}) error {
+	changes, err := Diff(old, new)
+	if err != nil {
+		return err
+	}
+	if len(changes.Set) > 0 {
+		if _, err := c.Run(ctx, menu+"/set", append([]string{"=.id=" + id}, changes.Set...)...); err != nil {
+			return err
+		}
+	}
+	for _, name := range changes.Unset {
+		if _, err := c.Run(ctx, menu+"/unset", "=.id="+id, "=value-name="+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func marshalFields(v interface{}) (reflect.Value, []structField, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return reflect.Value{}, nil, errors.New("routeros: Marshal of nil")
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return reflect.Value{}, nil, errors.New("routeros: Marshal of nil")
+	}
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+	writable := fields[:0]
+	for _, f := range fields {
+		if f.name != ".id" && !f.readOnly {
+			writable = append(writable, f)
+		}
+	}
+	return rv, writable, nil
+}
+
+// fieldValue returns the formatted value of the field and whether it is set
+func fieldValue(rv reflect.Value, f structField) (string, bool, error) {
+	field := rv.FieldByIndex(f.index)
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return "", false, nil
+		}
+		field = field.Elem()
+	} else if f.omitEmpty && field.IsZero() {
+		return "", false, nil
+	}
+	value, err := formatValue(field)
+	if err != nil {
+		return "", false, &FieldError{
+			Attribute: f.name,
+			Field:     rv.Type().FieldByIndex(f.index).Name,
+			Err:       err,
+		}
+	}
+	return value, true, nil
+}
+
+func formatValue(field reflect.Value) (string, error) {
+	if field.Type().Implements(textMarshalerType) {
+		text, err := field.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	if field.CanAddr() && field.Addr().Type().Implements(textMarshalerType) {
+		text, err := field.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	switch field.Type() {
+	case durationType:
+		return rostypes.FormatDuration(time.Duration(field.Int())), nil
+	case ipType:
+		if field.IsNil() {
+			return "", nil
+		}
+		return field.Interface().(net.IP).String(), nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return rostypes.FormatBool(field.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'f', -1, 64), nil
+	case reflect.Slice:
+		parts := make([]string, field.Len())
+		for i := range parts {
+			part, err := formatValue(field.Index(i))
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, ","), nil
+	}
+	return "", fmt.Errorf("unsupported field type %s", field.Type())
+}
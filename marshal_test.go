@@ -0,0 +1,113 @@
+package go_routeros
+
+import (
+	"context"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/leandrose/go-routeros/routerossim"
+)
+
+type testSecret struct {
+	ID       ID            `routeros:".id"`
+	Name     string        `routeros:"name"`
+	Password string        `routeros:"password,omitempty"`
+	Comment  *string       `routeros:"comment"`
+	Disabled bool          `routeros:"disabled"`
+	Timeout  time.Duration `routeros:"session-timeout,omitempty"`
+	Address  netip.Addr    `routeros:"remote-address,omitempty"`
+	Routes   []string      `routeros:"routes,omitempty"`
+	Active   bool          `routeros:"active,readonly"`
+}
+
+func TestMarshal(t *testing.T) {
+	empty := ""
+	words, err := Marshal(&testSecret{
+		ID:       "*1",
+		Name:     "alice smith",
+		Comment:  &empty,
+		Disabled: true,
+		Timeout:  26*time.Hour + 90*time.Second,
+		Address:  netip.MustParseAddr("10.0.0.2"),
+		Routes:   []string{"10.1.0.0/24", "10.2.0.0/24"},
+		Active:   true,
+	})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	expected := []string{
+		"=name=alice smith",
+		"=comment=",
+		"=disabled=yes",
+		"=session-timeout=1d2h1m30s",
+		"=remote-address=10.0.0.2",
+		"=routes=10.1.0.0/24,10.2.0.0/24",
+	}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("expected: %q, got: %q", expected, words)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	comment := "a=b c"
+	old := testSecret{Name: "alice", Password: "x", Comment: &comment, Timeout: time.Hour}
+	new := old
+	new.Password = "y"
+	new.Comment = nil
+	new.Timeout = 0
+	new.Active = true
+
+	changes, err := Diff(old, &new)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if !reflect.DeepEqual(changes.Set, []string{"=password=y"}) {
+		t.Errorf("unexpected set: %q", changes.Set)
+	}
+	if !reflect.DeepEqual(changes.Unset, []string{"comment", "session-timeout"}) {
+		t.Errorf("unexpected unset: %q", changes.Unset)
+	}
+
+	changes, err = Diff(old, old)
+	if err != nil || !changes.IsEmpty() {
+		t.Errorf("expected no changes, got: %+v %v", changes, err)
+	}
+	if _, err := Diff(old, testInterface{}); err == nil {
+		t.Errorf("expected an error for different types")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	sim := routerossim.New()
+	client := NewClient(sim.Server().Pipe())
+	if err := client.Login("admin", ""); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	comment := "old"
+	old := testSecret{Name: "alice", Password: "x", Comment: &comment}
+	words, err := Marshal(old)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	reply, err := client.Run(ctx, "/ppp/secret/add", words...)
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	new := old
+	new.Comment = nil
+	new.Disabled = true
+	if err := client.Update(ctx, "/ppp/secret", reply.Ret(), old, new); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	items := sim.Items("/ppp/secret")
+	if _, ok := items[0]["comment"]; ok || items[0]["disabled"] != "yes" {
+		t.Errorf("unexpected state: %v", items[0])
+	}
+}
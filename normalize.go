@@ -4,88 +4,72 @@ import (
 	"strings"
 )
 
+// NormalizeToCommandLine renders an API command as CLI text, e.g. for audit
+// logs. The query words are evaluated with the RouterOS stack rules and become
+// a where clause for print and a [find ...] for commands acting on items.
+// Repeated attributes are rendered once with the last value, as the router
+// applies them.
 func NormalizeToCommandLine(menu string, sentence ...string) string {
 	if len(sentence) == 0 {
 		return menu
 	}
 
-	type group struct {
-		type_ string
-		conds []string
-	}
-
-	var groups []group
-	var current group
-	current.type_ = "and"
-	var preArgs []string
-
+	var keys []string
+	values := make(map[string]string)
+	var queries []string
 	for _, s := range sentence {
-		switch s {
-		case "?#|":
-			current.type_ = "or"
-			if len(current.conds) > 0 {
-				groups = append(groups, current)
+		switch {
+		case strings.HasPrefix(s, "?"):
+			queries = append(queries, s)
+		case strings.HasPrefix(s, "="):
+			key, value, _ := strings.Cut(s[1:], "=")
+			if _, ok := values[key]; !ok {
+				keys = append(keys, key)
 			}
-			current = group{type_: "and"}
-		case "?#&":
-			if len(current.conds) > 0 {
-				groups = append(groups, current)
-			}
-			current = group{type_: "and"}
-		case "?#()":
-			continue
-		default:
-			if strings.HasPrefix(s, "=") {
-				split := strings.SplitN(strings.TrimPrefix(s, "="), "=", 2)
-				if strings.Contains(split[1], " ") {
-					split[1] = "\"" + split[1] + "\""
-				}
-				preArgs = append(preArgs, strings.Join(split, "="))
-			} else {
-				s = strings.TrimPrefix(s, "?")
-				current.conds = append(current.conds, s)
-			}
-		}
-	}
-
-	if len(current.conds) > 0 {
-		groups = append(groups, current)
-	}
-
-	var parts []string
-	for _, g := range groups {
-		if len(g.conds) == 0 {
-			continue
-		}
-		groupExpr := strings.Join(g.conds, " "+g.type_+" ")
-		// só coloca parênteses se houver múltiplos grupos
-		if len(groups) > 1 && len(g.conds) > 1 {
-			parts = append(parts, "("+groupExpr+")")
-		} else {
-			parts = append(parts, groupExpr)
+			values[key] = value
 		}
 	}
 
 	segments := strings.SplitAfter(menu, "/")
 	cmd := strings.TrimSpace(segments[len(segments)-1])
-	pre := strings.TrimRight(" "+strings.Join(preArgs, " "), " ")
-	if len(parts) == 0 {
-		switch cmd {
-		case "add", "set":
-			return menu + pre
+
+	var pre []string
+	for _, key := range keys {
+		switch {
+		case key == ".id" && cmd != "add" && cmd != "print":
+			// the item reference comes right after the command
+			pre = append([]string{QuoteValue(values[key])}, pre...)
+		case strings.HasPrefix(key, "."):
+			pre = append(pre, key[1:]+"="+QuoteValue(values[key]))
 		default:
-			return menu
+			pre = append(pre, key+"="+QuoteValue(values[key]))
 		}
 	}
+	line := menu
+	if len(pre) > 0 {
+		line += " " + strings.Join(pre, " ")
+	}
+
+	if len(queries) == 0 {
+		return line
+	}
+	query, err := ParseQuery(queries...)
+	if err != nil {
+		// keep the words that could not be evaluated instead of hiding them
+		return line + " " + strings.Join(queries, " ")
+	}
+	if query.IsEmpty() {
+		return line
+	}
 
 	switch cmd {
 	case "print":
-		return menu + " where " + strings.Join(parts, " and ")
+		return line + " where " + query.String()
 	case "set", "remove", "unset", "disable", "enable":
-		return menu + pre + " [find " + strings.Join(parts, " and ") + "]"
+		return line + " [find " + query.String() + "]"
 	case "add":
-		return menu + pre
+		return line
 	default:
-		return menu + " " + strings.Join(parts, " ")
+		return line + " " + query.String()
 	}
 }
@@ -68,3 +68,94 @@ func TestNormalizeToCommandLineByPrint(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeToCommandLineQueryStack(t *testing.T) {
+	tests := []struct {
+		name     string
+		menu     string
+		args     []string
+		expected string
+	}{
+		{
+			"Print com NOT",
+			"/interface/print",
+			[]string{"?type=ether", "?#!"},
+			"/interface/print where type!=ether",
+		},
+		{
+			"Print com NOT de grupo",
+			"/interface/print",
+			[]string{"?type=ether", "?type=vlan", "?#|!", "?disabled=false"},
+			"/interface/print where !(type=ether or type=vlan) and disabled=false",
+		},
+		{
+			"Print com Missing, Has, menor e maior",
+			"/ip/route/print",
+			[]string{"?-comment", "?gateway", "?<distance=10", "?>distance=1"},
+			"/ip/route/print where !comment and gateway and distance<10 and distance>1",
+		},
+		{
+			"Print com AND dentro de OR",
+			"/ip/firewall/address-list/print",
+			[]string{"?list=a", "?disabled=no", "?#&", "?list=b", "?#|"},
+			"/ip/firewall/address-list/print where list=a and disabled=no or list=b",
+		},
+		{
+			"Print com indice no final substitui a pilha",
+			"/interface/print",
+			[]string{"?type=ether", "?type=vlan", "?#1"},
+			"/interface/print where type=ether",
+		},
+		{
+			"Print com copia por indice",
+			"/interface/print",
+			[]string{"?type=ether", "?running=true", "?#1.!|"},
+			"/interface/print where type=ether and (running=true or type!=ether)",
+		},
+		{
+			"Print com ponto copia o topo",
+			"/interface/print",
+			[]string{"?type=ether", "?#.!|"},
+			"/interface/print where type=ether or type!=ether",
+		},
+		{
+			"Print com proplist",
+			"/interface/print",
+			[]string{"=.proplist=name,type", "?running=true"},
+			"/interface/print proplist=name,type where running=true",
+		},
+		{
+			"Set com caracteres especiais",
+			"/ip/firewall/filter/set",
+			[]string{"=comment=say \"hi\" to $user\\", "?comment=a b"},
+			`/ip/firewall/filter/set comment="say \"hi\" to \$user\\" [find comment="a b"]`,
+		},
+		{
+			"Set por .id",
+			"/interface/set",
+			[]string{"=mtu=1500", "=.id=*1"},
+			"/interface/set *1 mtu=1500",
+		},
+		{
+			"Valor vazio",
+			"/ip/address/set",
+			[]string{"=.id=*2", "=comment="},
+			`/ip/address/set *2 comment=""`,
+		},
+		{
+			"Query invalida",
+			"/interface/print",
+			[]string{"?#|"},
+			"/interface/print ?#|",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NormalizeToCommandLine(tt.menu, tt.args...)
+			if result != tt.expected {
+				t.Errorf("expected: %s, got: %s", tt.expected, result)
+			}
+		})
+	}
+}
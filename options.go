@@ -0,0 +1,133 @@
+package go_routeros
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// Option configures a client created by Dial or NewClient
+type Option func(*config)
+
+type config struct {
+	tlsConfig    *tls.Config
+	dialer       *net.Dialer
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+	loginTimeout time.Duration
+	loginMode    LoginMode
+	delivery     Delivery
+	logger       *slog.Logger
+	newTags      func() TagStrategy
+	pinnedKeys   []string
+	knownHosts   *KnownHosts
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithTLS connects with API-SSL, usually on port 8729
+func WithTLS(tlsConfig *tls.Config) Option {
+	return func(cfg *config) {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		cfg.tlsConfig = tlsConfig
+	}
+}
+
+// WithDialer uses dialer to connect, e.g. to set a local address or a
+// keepalive period of TCP
+func WithDialer(dialer *net.Dialer) Option {
+	return func(cfg *config) {
+		cfg.dialer = dialer
+	}
+}
+
+// WithReadTimeout bounds the time to receive a sentence once its first byte
+// arrived. Idle connections are not affected, see EnableKeepalive.
+func WithReadTimeout(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.readTimeout = d
+	}
+}
+
+// WithWriteTimeout bounds the time to write a sentence
+func WithWriteTimeout(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.writeTimeout = d
+	}
+}
+
+// WithLoginTimeout bounds the time of the login exchange
+func WithLoginTimeout(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.loginTimeout = d
+	}
+}
+
+// WithDelivery sets the Delivery of the commands sent without one, the
+// default is Unbounded
+func WithDelivery(delivery Delivery) Option {
+	return func(cfg *config) {
+		cfg.delivery = delivery
+	}
+}
+
+// WithResponseBuffer keeps up to size pending replies per command, the replies
+// received while the buffer is full are dropped and counted in
+// Request.Dropped. Use WithDelivery(CancelOnOverflow(size)) to cancel the
+// command instead.
+func WithResponseBuffer(size int) Option {
+	return WithDelivery(DropOnOverflow(size))
+}
+
+// WithLogger logs to logger, see SetLogger
+func WithLogger(logger *slog.Logger) Option {
+	return func(cfg *config) {
+		cfg.logger = logger
+	}
+}
+
+// WithTagStrategy chooses the .tag of the commands, the default is
+// SequentialTags(0). newStrategy is called once per client, so the options can
+// be shared by several clients such as the connections of a Pool.
+func WithTagStrategy(newStrategy func() TagStrategy) Option {
+	return func(cfg *config) {
+		cfg.newTags = newStrategy
+	}
+}
+
+// TagStrategy returns the .tag of the next command, it is called by one
+// goroutine at a time. A tag still used by a running command is skipped and
+// the next one is requested.
+type TagStrategy func() int
+
+// SequentialTags counts up from start
+func SequentialTags(start int) TagStrategy {
+	next := start
+	return func() int {
+		tag := next
+		next++
+		if next < 0 {
+			next = 0
+		}
+		return tag
+	}
+}
+
+// RandomTags returns random tags, so the commands of different clients can
+// not be confused in the logs of the router
+func RandomTags() TagStrategy {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return func() int {
+		return r.Intn(1 << 31)
+	}
+}
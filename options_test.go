@@ -0,0 +1,130 @@
+package go_routeros
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/leandrose/go-routeros/routerostest"
+)
+
+func TestOptions(t *testing.T) {
+	t.Run("Estratégia de tags", func(t *testing.T) {
+		server := routerostest.NewServer()
+		client := NewClient(server.Pipe(), WithTagStrategy(func() TagStrategy { return SequentialTags(100) }))
+		if err := client.Login("admin", ""); err != nil {
+			t.Fatalf("login: %v", err)
+		}
+		defer client.Close()
+		// the login reads the identity with the first tag
+		if received := server.Received(); len(received) == 0 || received[0].Tag != "100" {
+			t.Errorf("expected the tag 100, got: %+v", received)
+		}
+	})
+
+	t.Run("Tags em uso são ignoradas", func(t *testing.T) {
+		tags := []int{1, 1, 2}
+		client := NewClient(discardConn{}, WithTagStrategy(func() TagStrategy {
+			return func() int {
+				tag := tags[0]
+				tags = tags[1:]
+				return tag
+			}
+		}))
+		client.responses[1] = &Request{}
+		if tag := client.nextTag(); tag != 2 {
+			t.Errorf("expected the tag 2, got: %d", tag)
+		}
+	})
+
+	t.Run("Estratégia de tags por cliente", func(t *testing.T) {
+		opts := []Option{WithTagStrategy(func() TagStrategy { return SequentialTags(100) })}
+		first, second := NewClient(discardConn{}, opts...), NewClient(discardConn{}, opts...)
+		for _, client := range []*Client{first, second} {
+			if tag := client.nextTag(); tag != 100 {
+				t.Errorf("expected each client to start at 100, got: %d", tag)
+			}
+		}
+	})
+
+	t.Run("Buffer de respostas", func(t *testing.T) {
+		client := NewClient(discardConn{}, WithResponseBuffer(10))
+		req, err := client.SendCommandContext(context.Background(), "/interface/listen")
+		if err != nil {
+			t.Fatalf("send: %v", err)
+		}
+		if req.delivery != DropOnOverflow(10) {
+			t.Errorf("expected the delivery of the client, got: %+v", req.delivery)
+		}
+		req, err = client.SendCommandWithOptions(context.Background(), CommandOptions{Delivery: CancelOnOverflow(5)}, "/interface/listen")
+		if err != nil {
+			t.Fatalf("send: %v", err)
+		}
+		if req.delivery != CancelOnOverflow(5) {
+			t.Errorf("expected the delivery of the command, got: %+v", req.delivery)
+		}
+	})
+
+	t.Run("Timeout de escrita", func(t *testing.T) {
+		conn, peer := net.Pipe()
+		defer peer.Close()
+		client := NewClient(conn, WithWriteTimeout(50*time.Millisecond))
+		defer client.Close()
+		if _, err := client.SendCommandContext(context.Background(), "/interface/print"); !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("expected a deadline error, got: %v", err)
+		}
+	})
+
+	t.Run("Timeout de leitura", func(t *testing.T) {
+		conn, peer := net.Pipe()
+		defer peer.Close()
+		client := NewClient(conn, WithReadTimeout(50*time.Millisecond))
+		defer client.Close()
+		go func() {
+			// an incomplete sentence
+			_, _ = peer.Write([]byte{3, '!', 'r'})
+		}()
+		if _, err := client.readSentence(); !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("expected a deadline error, got: %v", err)
+		}
+	})
+
+	t.Run("Timeout de login", func(t *testing.T) {
+		conn, peer := net.Pipe()
+		defer peer.Close()
+		go func() {
+			// read the login and never answer
+			buf := make([]byte, 1024)
+			for {
+				if _, err := peer.Read(buf); err != nil {
+					return
+				}
+			}
+		}()
+		client := NewClient(conn, WithLoginTimeout(50*time.Millisecond))
+		defer client.Close()
+		if err := client.Login("admin", ""); !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("expected a deadline error, got: %v", err)
+		}
+	})
+
+	t.Run("Dial com dialer", func(t *testing.T) {
+		server := routerostest.NewServer()
+		addr, err := server.Listen()
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		defer server.Close()
+		client, err := Dial(context.Background(), addr, WithDialer(&net.Dialer{Timeout: time.Second}))
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		defer client.Close()
+		if err := client.Login("admin", ""); err != nil {
+			t.Fatalf("login: %v", err)
+		}
+	})
+}
@@ -0,0 +1,472 @@
+package go_routeros
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed is returned by a Pool after Close
+var ErrPoolClosed = errors.New("pool closed")
+
+// PoolOptions options of a Pool
+type PoolOptions struct {
+	// Size the connections kept open, 2 when zero
+	Size int
+	// MaxSize caps the connections, including the dedicated ones. More than
+	// Size are opened while every connection is busy, 2*Size when zero.
+	MaxSize int
+	// Dedicated reports whether a command gets a connection of its own for as
+	// long as it runs, IsStreaming when nil
+	Dedicated func(cmd string, args []string) bool
+	// HealthCheckInterval how often the idle connections are checked with
+	// /system/identity/print, 30 seconds when zero. Dead connections are
+	// replaced and the surplus above Size is closed.
+	HealthCheckInterval time.Duration
+	// HealthCheckTimeout the time to answer a health check, 5 seconds when zero
+	HealthCheckTimeout time.Duration
+}
+
+// PoolStats describes the connections of a Pool
+type PoolStats struct {
+	// Open the connections, including the dedicated ones
+	Open int
+	// Dedicated the connections held by a streaming command or Acquire
+	Dedicated int
+	// InFlight the commands running on the connections
+	InFlight int
+}
+
+// Pool keeps several logged-in connections to one router, so a long print
+// does not hold up the commands behind it. Commands go to the connection
+// with the fewest running commands, streaming commands get a connection of
+// their own.
+type Pool struct {
+	dial func(ctx context.Context) (*Client, error)
+	opts PoolOptions
+
+	mu       sync.Mutex
+	conns    []*pooledConn
+	dialing  int
+	released chan struct{}
+	closed   bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type pooledConn struct {
+	client    *Client
+	dedicated bool
+	// pending the commands given the connection by get and not released yet
+	pending  int
+	lastUsed time.Time
+}
+
+// load the commands running or about to run on the connection, p.mu must be held
+func (pc *pooledConn) load() int {
+	return max(pc.pending, pc.client.inFlight())
+}
+
+// DialPool opens a Pool of connections to addr, each dialed with opts and
+// logged in with username and password
+func DialPool(ctx context.Context, addr, username, password string, poolOpts PoolOptions, opts ...Option) (*Pool, error) {
+	return NewPool(ctx, func(ctx context.Context) (*Client, error) {
+		client, err := Dial(ctx, addr, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if err = client.LoginContext(ctx, username, password); err != nil {
+			client.Close()
+			return nil, err
+		}
+		return client, nil
+	}, poolOpts)
+}
+
+// NewPool opens Size connections with dial, which returns a logged-in client
+func NewPool(ctx context.Context, dial func(ctx context.Context) (*Client, error), opts PoolOptions) (*Pool, error) {
+	if opts.Size <= 0 {
+		opts.Size = 2
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = 2 * opts.Size
+	}
+	if opts.MaxSize < opts.Size {
+		opts.MaxSize = opts.Size
+	}
+	if opts.Dedicated == nil {
+		opts.Dedicated = IsStreaming
+	}
+	if opts.HealthCheckInterval <= 0 {
+		opts.HealthCheckInterval = 30 * time.Second
+	}
+	if opts.HealthCheckTimeout <= 0 {
+		opts.HealthCheckTimeout = 5 * time.Second
+	}
+	p := &Pool{
+		dial:     dial,
+		opts:     opts,
+		released: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for i := 0; i < opts.Size; i++ {
+		client, err := dial(ctx)
+		if err != nil {
+			p.closeConns()
+			return nil, err
+		}
+		p.conns = append(p.conns, &pooledConn{client: client, lastUsed: time.Now()})
+	}
+	var healthCtx context.Context
+	healthCtx, p.cancel = context.WithCancel(context.Background())
+	go p.healthCheck(healthCtx)
+	return p, nil
+}
+
+// IsStreaming reports whether a command runs until it is cancelled, such as
+// listen, a print with follow or interval, or a monitor without once
+func IsStreaming(cmd string, args []string) bool {
+	switch cmd[strings.LastIndexByte(cmd, '/')+1:] {
+	case "listen", "torch", "sniff", "bandwidth-test":
+		return true
+	case "monitor", "monitor-traffic":
+		return !hasArg(args, "once")
+	case "ping":
+		return !hasArg(args, "count")
+	}
+	return hasArg(args, "follow") || hasArg(args, "follow-only") || hasArg(args, "interval")
+}
+
+func hasArg(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == "="+name || strings.HasPrefix(arg, "="+name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// SendCommandContext sends a command on the connection chosen by the policy
+// of the pool, see Client.SendCommandContext
+func (p *Pool) SendCommandContext(ctx context.Context, cmd string, args ...string) (*Request, error) {
+	return p.SendCommandWithOptions(ctx, CommandOptions{}, cmd, args...)
+}
+
+// SendCommandWithOptions is like SendCommandContext with options for the
+// command. It waits for a free connection when a dedicated one is needed and
+// MaxSize is reached.
+func (p *Pool) SendCommandWithOptions(ctx context.Context, opts CommandOptions, cmd string, args ...string) (*Request, error) {
+	dedicated := p.opts.Dedicated(cmd, args)
+	pc, err := p.get(ctx, dedicated)
+	if err != nil {
+		return nil, err
+	}
+	req, err := pc.client.SendCommandWithOptions(ctx, opts, cmd, args...)
+	if err != nil {
+		p.release(pc, dedicated)
+		return nil, err
+	}
+	go func() {
+		<-req.Done()
+		p.release(pc, dedicated)
+	}()
+	return req, nil
+}
+
+// Run sends a command with SendCommandContext and waits for it to finish,
+// see Client.Run
+func (p *Pool) Run(ctx context.Context, cmd string, args ...string) (*Reply, error) {
+	req, err := p.SendCommandContext(ctx, cmd, args...)
+	if err != nil {
+		return nil, err
+	}
+	return collectReply(ctx, req)
+}
+
+// Acquire takes a connection out of the pool until Release, e.g. for Watch or
+// FollowLog
+func (p *Pool) Acquire(ctx context.Context) (*Client, error) {
+	pc, err := p.get(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	return pc.client, nil
+}
+
+// Release returns a connection taken with Acquire, its commands should have
+// finished
+func (p *Pool) Release(client *Client) {
+	p.mu.Lock()
+	for _, pc := range p.conns {
+		if pc.client == client && pc.dedicated {
+			p.mu.Unlock()
+			p.release(pc, true)
+			return
+		}
+	}
+	p.mu.Unlock()
+}
+
+// Stats returns the current connections
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := PoolStats{Open: len(p.conns)}
+	for _, pc := range p.conns {
+		if pc.dedicated {
+			stats.Dedicated++
+		}
+		stats.InFlight += pc.client.inFlight()
+	}
+	return stats
+}
+
+// Close closes every connection, the running commands fail with ErrClosed
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	p.cancel()
+	p.signal()
+	p.mu.Unlock()
+	<-p.done
+	p.closeConns()
+}
+
+func (p *Pool) closeConns() {
+	p.mu.Lock()
+	conns := p.conns
+	p.conns = nil
+	p.mu.Unlock()
+	for _, pc := range conns {
+		pc.client.Close()
+	}
+}
+
+// get returns the connection for a command, reserved until release so that no
+// other call takes it for a dedicated command in the meantime
+func (p *Pool) get(ctx context.Context, dedicated bool) (*pooledConn, error) {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+		p.removeDead()
+		least := p.leastBusy()
+		busy := least == nil || least.load() > 0
+		total := len(p.conns) + p.dialing
+		switch {
+		case !busy:
+			least.reserve(dedicated)
+			p.mu.Unlock()
+			return least, nil
+		case total < p.opts.MaxSize:
+			p.dialing++
+			p.mu.Unlock()
+			pc, err := p.open(ctx, dedicated)
+			if err == nil || dedicated {
+				return pc, err
+			}
+			// a busy connection is better than none
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if least = p.leastBusy(); least == nil {
+				return nil, err
+			}
+			least.reserve(false)
+			return least, nil
+		case !dedicated && least != nil:
+			least.reserve(false)
+			p.mu.Unlock()
+			return least, nil
+		}
+		released := p.released
+		p.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+// reserve gives the connection to a command, p.mu must be held
+func (pc *pooledConn) reserve(dedicated bool) {
+	pc.pending++
+	if dedicated {
+		pc.dedicated = true
+	}
+	pc.lastUsed = time.Now()
+}
+
+// open dials a connection counted in p.dialing, adds it to the pool and
+// reserves it
+func (p *Pool) open(ctx context.Context, dedicated bool) (*pooledConn, error) {
+	client, err := p.dial(ctx)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.dialing--
+	if err != nil {
+		p.signal()
+		return nil, err
+	}
+	if p.closed {
+		client.Close()
+		return nil, ErrPoolClosed
+	}
+	pc := &pooledConn{client: client}
+	pc.reserve(dedicated)
+	p.conns = append(p.conns, pc)
+	return pc, nil
+}
+
+// leastBusy returns the shared connection with the lowest load,
+// p.mu must be held
+func (p *Pool) leastBusy() *pooledConn {
+	var least *pooledConn
+	leastLoad := 0
+	for _, pc := range p.conns {
+		if pc.dedicated {
+			continue
+		}
+		if n := pc.load(); least == nil || n < leastLoad {
+			least, leastLoad = pc, n
+		}
+	}
+	return least
+}
+
+// removeDead drops the connections that were closed, p.mu must be held
+func (p *Pool) removeDead() {
+	conns := p.conns[:0]
+	for _, pc := range p.conns {
+		if pc.client.IsConnected() {
+			conns = append(conns, pc)
+		} else {
+			pc.client.Close()
+		}
+	}
+	for i := len(conns); i < len(p.conns); i++ {
+		p.conns[i] = nil
+	}
+	p.conns = conns
+}
+
+// release ends a reservation of get, a dedicated connection is shared again
+// once its dedicated command releases it
+func (p *Pool) release(pc *pooledConn, dedicated bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if dedicated {
+		pc.dedicated = false
+	}
+	pc.lastUsed = time.Now()
+	p.unreserve(pc)
+}
+
+// unreserve ends a reservation without marking the connection as used, p.mu
+// must be held
+func (p *Pool) unreserve(pc *pooledConn) {
+	if pc.pending > 0 {
+		pc.pending--
+	}
+	p.signal()
+}
+
+// signal wakes up the commands waiting for a connection, p.mu must be held
+func (p *Pool) signal() {
+	close(p.released)
+	p.released = make(chan struct{})
+}
+
+func (p *Pool) healthCheck(ctx context.Context) {
+	defer close(p.done)
+	ticker := time.NewTicker(p.opts.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, pc := range p.idle() {
+			checkCtx, cancel := context.WithTimeout(ctx, p.opts.HealthCheckTimeout)
+			_, err := pc.client.Run(checkCtx, "/system/identity/print")
+			cancel()
+			// a health check does not keep a surplus connection open
+			p.mu.Lock()
+			p.unreserve(pc)
+			p.mu.Unlock()
+			if ctx.Err() != nil {
+				return
+			}
+			// a !trap is still an answer
+			var rosErr *RouterOSError
+			if err != nil && (!errors.As(err, &rosErr) || rosErr.Fatal) {
+				if logger := pc.client.log(); logger != nil {
+					logger.Warn("pooled connection failed the health check", "error", err)
+				}
+				pc.client.Close()
+			}
+		}
+		p.trim()
+		p.fill(ctx)
+	}
+}
+
+// idle reserves the shared connections unused for a health check interval
+func (p *Pool) idle() []*pooledConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var idle []*pooledConn
+	for _, pc := range p.conns {
+		if !pc.dedicated && pc.load() == 0 && time.Since(pc.lastUsed) >= p.opts.HealthCheckInterval {
+			pc.pending++
+			idle = append(idle, pc)
+		}
+	}
+	return idle
+}
+
+// trim closes the dead connections and the idle ones above Size
+func (p *Pool) trim() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.removeDead()
+	surplus := len(p.conns) - p.opts.Size
+	for i := len(p.conns) - 1; i >= 0 && surplus > 0; i-- {
+		pc := p.conns[i]
+		if pc.dedicated || pc.load() > 0 || time.Since(pc.lastUsed) < p.opts.HealthCheckInterval {
+			continue
+		}
+		pc.client.Close()
+		p.conns = append(p.conns[:i], p.conns[i+1:]...)
+		surplus--
+	}
+}
+
+// fill dials the connections missing to reach Size, a failed dial is retried
+// at the next health check
+func (p *Pool) fill(ctx context.Context) {
+	for {
+		p.mu.Lock()
+		if p.closed || len(p.conns)+p.dialing >= p.opts.Size {
+			p.mu.Unlock()
+			return
+		}
+		p.dialing++
+		p.mu.Unlock()
+		pc, err := p.open(ctx, false)
+		if err != nil {
+			return
+		}
+		p.release(pc, false)
+	}
+}
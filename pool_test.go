@@ -0,0 +1,251 @@
+package go_routeros
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/leandrose/go-routeros/routerostest"
+)
+
+func newTestPool(t *testing.T, server *routerostest.Server, opts PoolOptions) (*Pool, *atomic.Int32) {
+	t.Helper()
+	server.Reply("/system/identity/print", routerostest.Re("=name=MikroTik"))
+	dials := new(atomic.Int32)
+	pool, err := NewPool(context.Background(), func(ctx context.Context) (*Client, error) {
+		dials.Add(1)
+		client := NewClient(server.Pipe())
+		if err := client.LoginContext(ctx, server.Username, server.Password); err != nil {
+			return nil, err
+		}
+		return client, nil
+	}, opts)
+	if err != nil {
+		t.Fatalf("pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool, dials
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestIsStreaming(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      string
+		args     []string
+		expected bool
+	}{
+		{"Listen", "/interface/listen", nil, true},
+		{"Print com follow", "/log/print", []string{"=follow=yes"}, true},
+		{"Print com follow-only", "/log/print", []string{"=follow-only="}, true},
+		{"Print com intervalo", "/interface/print", []string{"=stats", "=interval=1s"}, true},
+		{"Monitor contínuo", "/interface/monitor-traffic", []string{"=interface=ether1"}, true},
+		{"Monitor uma vez", "/interface/monitor-traffic", []string{"=interface=ether1", "=once="}, false},
+		{"Ping sem contagem", "/ping", []string{"=address=10.0.0.1"}, true},
+		{"Ping com contagem", "/ping", []string{"=address=10.0.0.1", "=count=3"}, false},
+		{"Print simples", "/ip/route/print", nil, false},
+		{"Argumento parecido", "/ip/route/print", []string{"=followers=1"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsStreaming(tt.cmd, tt.args); got != tt.expected {
+				t.Errorf("expected: %v, got: %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPool(t *testing.T) {
+	t.Run("Comandos vão para a conexão menos ocupada", func(t *testing.T) {
+		server := routerostest.NewServer()
+		server.Reply("/ip/route/print", routerostest.Re("=dst-address=0.0.0.0/0"), routerostest.Block())
+		pool, dials := newTestPool(t, server, PoolOptions{Size: 2, MaxSize: 2})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		if _, err := pool.SendCommandContext(ctx, "/ip/route/print"); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+		runCtx, runCancel := context.WithTimeout(context.Background(), time.Second)
+		defer runCancel()
+		if _, err := pool.Run(runCtx, "/system/identity/print"); err != nil {
+			t.Fatalf("expected the other connection to answer, got: %v", err)
+		}
+		if stats := pool.Stats(); stats.Open != 2 || stats.InFlight != 1 || stats.Dedicated != 0 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+		if dials.Load() != 2 {
+			t.Errorf("expected 2 dials, got: %d", dials.Load())
+		}
+	})
+
+	t.Run("Comando de streaming usa conexão dedicada", func(t *testing.T) {
+		server := routerostest.NewServer()
+		server.Reply("/interface/listen", routerostest.Block())
+		pool, _ := newTestPool(t, server, PoolOptions{Size: 1, MaxSize: 2})
+
+		req, err := pool.SendCommandContext(context.Background(), "/interface/listen")
+		if err != nil {
+			t.Fatalf("send: %v", err)
+		}
+		if stats := pool.Stats(); stats.Dedicated != 1 {
+			t.Errorf("expected a dedicated connection, got: %+v", stats)
+		}
+		if _, err = pool.Run(context.Background(), "/system/identity/print"); err != nil {
+			t.Fatalf("run: %v", err)
+		}
+		if stats := pool.Stats(); stats.Open != 2 {
+			t.Errorf("expected a second connection, got: %+v", stats)
+		}
+		_ = req.Cancel()
+		waitFor(t, func() bool { return pool.Stats().Dedicated == 0 })
+	})
+
+	t.Run("Espera uma conexão no limite", func(t *testing.T) {
+		server := routerostest.NewServer()
+		server.Reply("/interface/listen", routerostest.Block())
+		pool, _ := newTestPool(t, server, PoolOptions{Size: 1, MaxSize: 1})
+
+		req, err := pool.SendCommandContext(context.Background(), "/interface/listen")
+		if err != nil {
+			t.Fatalf("send: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if _, err = pool.SendCommandContext(ctx, "/interface/listen"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected to wait for a connection, got: %v", err)
+		}
+
+		acquired := make(chan *Client)
+		go func() {
+			client, err := pool.Acquire(context.Background())
+			if err != nil {
+				t.Errorf("acquire: %v", err)
+			}
+			acquired <- client
+		}()
+		_ = req.Cancel()
+		client := <-acquired
+		if stats := pool.Stats(); stats.Open != 1 || stats.Dedicated != 1 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+		pool.Release(client)
+		if stats := pool.Stats(); stats.Dedicated != 0 {
+			t.Errorf("expected the connection to be released, got: %+v", stats)
+		}
+	})
+
+	t.Run("Verificação de saúde substitui conexões fechadas", func(t *testing.T) {
+		server := routerostest.NewServer()
+		pool, dials := newTestPool(t, server, PoolOptions{Size: 2, HealthCheckInterval: 10 * time.Millisecond})
+
+		server.CloseConnections()
+		waitFor(t, func() bool { return dials.Load() >= 4 && pool.Stats().Open == 2 })
+		if _, err := pool.Run(context.Background(), "/system/identity/print"); err != nil {
+			t.Fatalf("run: %v", err)
+		}
+	})
+
+	t.Run("Conexões acima do tamanho são fechadas", func(t *testing.T) {
+		server := routerostest.NewServer()
+		server.Reply("/ip/route/print", routerostest.Block())
+		pool, _ := newTestPool(t, server, PoolOptions{Size: 1, MaxSize: 3, HealthCheckInterval: 10 * time.Millisecond})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		for i := 0; i < 3; i++ {
+			if _, err := pool.SendCommandContext(ctx, "/ip/route/print"); err != nil {
+				t.Fatalf("send: %v", err)
+			}
+		}
+		if stats := pool.Stats(); stats.Open != 3 {
+			t.Errorf("expected 3 connections, got: %+v", stats)
+		}
+		cancel()
+		waitFor(t, func() bool { return pool.Stats().Open == 1 })
+	})
+
+	t.Run("Reserva concorrente", func(t *testing.T) {
+		server := routerostest.NewServer()
+		pool, _ := newTestPool(t, server, PoolOptions{Size: 1, MaxSize: 3})
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		// pendingOf returns the reservations of the connection of client
+		pendingOf := func(client *Client) int {
+			pool.mu.Lock()
+			defer pool.mu.Unlock()
+			for _, pc := range pool.conns {
+				if pc.client == client {
+					return pc.pending
+				}
+			}
+			return -1
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					pc, err := pool.get(ctx, false)
+					if err != nil {
+						t.Errorf("get: %v", err)
+						return
+					}
+					pool.mu.Lock()
+					dedicated := pc.dedicated
+					pool.mu.Unlock()
+					if dedicated {
+						t.Errorf("a shared command got a dedicated connection")
+					}
+					pool.release(pc, false)
+					if _, err := pool.Run(ctx, "/system/identity/print"); err != nil {
+						t.Errorf("run: %v", err)
+						return
+					}
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 5; j++ {
+					client, err := pool.Acquire(ctx)
+					if err != nil {
+						t.Errorf("acquire: %v", err)
+						return
+					}
+					if n := pendingOf(client); n != 1 {
+						t.Errorf("expected the acquired connection to be reserved once, got: %d", n)
+					}
+					pool.Release(client)
+				}
+			}()
+		}
+		wg.Wait()
+		if stats := pool.Stats(); stats.Dedicated != 0 || stats.Open > 3 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+
+	t.Run("Pool fechado", func(t *testing.T) {
+		server := routerostest.NewServer()
+		pool, _ := newTestPool(t, server, PoolOptions{Size: 1})
+		pool.Close()
+		if _, err := pool.Run(context.Background(), "/system/identity/print"); !errors.Is(err, ErrPoolClosed) {
+			t.Errorf("expected ErrPoolClosed, got: %v", err)
+		}
+	})
+}
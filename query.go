@@ -0,0 +1,334 @@
+package go_routeros
+
+import (
+	"strings"
+
+	"github.com/leandrose/go-routeros/internal/rosquery"
+)
+
+// Query is a query expression of a print, compiled to RouterOS API query
+// words with Words and rendered as CLI syntax with String. Build it with Where:
+//
+//	Where("name").Eq("x").Or(Where("disabled").Eq("yes"))
+type Query struct {
+	node node
+}
+
+// Field is an attribute name used to build a condition
+type Field string
+
+// Where starts a condition on the attribute name
+func Where(name string) Field {
+	return Field(name)
+}
+
+// Eq matches items whose attribute equals value
+func (f Field) Eq(value string) Query {
+	return Query{node: &condNode{op: opEq, name: string(f), value: value}}
+}
+
+// Lt matches items whose attribute is less than value
+func (f Field) Lt(value string) Query {
+	return Query{node: &condNode{op: opLt, name: string(f), value: value}}
+}
+
+// Gt matches items whose attribute is greater than value
+func (f Field) Gt(value string) Query {
+	return Query{node: &condNode{op: opGt, name: string(f), value: value}}
+}
+
+// Has matches items that have a value for the attribute
+func (f Field) Has() Query {
+	return Query{node: &condNode{op: opHas, name: string(f)}}
+}
+
+// Missing matches items that have no value for the attribute
+func (f Field) Missing() Query {
+	return Query{node: &condNode{op: opMissing, name: string(f)}}
+}
+
+// And matches items matched by q and by every other query
+func (q Query) And(others ...Query) Query {
+	return And(append([]Query{q}, others...)...)
+}
+
+// Or matches items matched by q or by any other query
+func (q Query) Or(others ...Query) Query {
+	return Or(append([]Query{q}, others...)...)
+}
+
+// Not matches items not matched by q
+func (q Query) Not() Query {
+	return Not(q)
+}
+
+// And matches items matched by every query
+func And(queries ...Query) Query {
+	return join(true, queries)
+}
+
+// Or matches items matched by any query
+func Or(queries ...Query) Query {
+	return join(false, queries)
+}
+
+// Not matches items not matched by q, the negation of the empty query
+// matches no item
+func Not(q Query) Query {
+	switch q.node.(type) {
+	case nil:
+		return Query{node: noneNode{}}
+	case noneNode:
+		return Query{}
+	}
+	return Query{node: &notNode{node: q.node}}
+}
+
+func join(and bool, queries []Query) Query {
+	var nodes []node
+	for _, q := range queries {
+		if q.node == nil {
+			continue
+		}
+		// flatten a chain of the same operator: a.Or(b).Or(c)
+		if b, ok := q.node.(*boolNode); ok && b.and == and {
+			nodes = append(nodes, b.nodes...)
+			continue
+		}
+		nodes = append(nodes, q.node)
+	}
+	switch len(nodes) {
+	case 0:
+		return Query{}
+	case 1:
+		return Query{node: nodes[0]}
+	}
+	return Query{node: &boolNode{and: and, nodes: nodes}}
+}
+
+// IsEmpty reports whether the query matches every item
+func (q Query) IsEmpty() bool {
+	return q.node == nil
+}
+
+// Words returns the API query words, e.g. ?name=x ?disabled=yes ?#|
+func (q Query) Words() []string {
+	if q.node == nil {
+		return nil
+	}
+	return q.node.words(nil)
+}
+
+// String returns the query in CLI syntax, e.g. name=x or disabled=yes
+func (q Query) String() string {
+	if q.node == nil {
+		return ""
+	}
+	return q.node.cli(precOr)
+}
+
+// Match reports whether the item matches the query, evaluated locally with
+// the semantics of RouterOS: < and > compare integers numerically and other
+// values as strings
+func (q Query) Match(item Sentence) bool {
+	if q.node == nil {
+		return true
+	}
+	return q.node.match(item)
+}
+
+const (
+	opEq      = rosquery.Eq
+	opLt      = rosquery.Lt
+	opGt      = rosquery.Gt
+	opHas     = rosquery.Has
+	opMissing = rosquery.Missing
+)
+
+// operator precedence in the CLI, a lower value binds looser
+const (
+	precOr = iota
+	precAnd
+	precNot
+)
+
+type node interface {
+	words(dst []string) []string
+	cli(parent int) string
+	match(item Sentence) bool
+}
+
+type condNode struct {
+	op    rosquery.Op
+	name  string
+	value string
+}
+
+func (n *condNode) words(dst []string) []string {
+	switch n.op {
+	case opLt:
+		return append(dst, "?<"+n.name+"="+n.value)
+	case opGt:
+		return append(dst, "?>"+n.name+"="+n.value)
+	case opHas:
+		return append(dst, "?"+n.name)
+	case opMissing:
+		return append(dst, "?-"+n.name)
+	}
+	return append(dst, "?"+n.name+"="+n.value)
+}
+
+func (n *condNode) cli(int) string {
+	switch n.op {
+	case opLt:
+		return n.name + "<" + QuoteValue(n.value)
+	case opGt:
+		return n.name + ">" + QuoteValue(n.value)
+	case opHas:
+		return n.name
+	case opMissing:
+		return "!" + n.name
+	}
+	return n.name + "=" + QuoteValue(n.value)
+}
+
+func (n *condNode) match(item Sentence) bool {
+	return rosquery.Condition{Op: n.op, Name: n.name, Value: n.value}.Match(item.Get(n.name))
+}
+
+// noneNode matches no item, every item has an .id
+type noneNode struct{}
+
+func (noneNode) words(dst []string) []string {
+	return append(dst, "?.id", "?-.id", "?#&")
+}
+
+func (noneNode) cli(int) string {
+	return "false"
+}
+
+func (noneNode) match(Sentence) bool {
+	return false
+}
+
+type notNode struct {
+	node node
+}
+
+func (n *notNode) words(dst []string) []string {
+	return append(n.node.words(dst), "?#!")
+}
+
+func (n *notNode) cli(int) string {
+	if c, ok := n.node.(*condNode); ok {
+		switch c.op {
+		case opEq:
+			return c.name + "!=" + QuoteValue(c.value)
+		case opHas:
+			return "!" + c.name
+		case opMissing:
+			return c.name
+		}
+	}
+	return "!(" + n.node.cli(precOr) + ")"
+}
+
+func (n *notNode) match(item Sentence) bool {
+	return !n.node.match(item)
+}
+
+type boolNode struct {
+	and   bool
+	nodes []node
+}
+
+func (n *boolNode) words(dst []string) []string {
+	for _, child := range n.nodes {
+		dst = child.words(dst)
+	}
+	op := "|"
+	if n.and {
+		op = "&"
+	}
+	return append(dst, "?#"+strings.Repeat(op, len(n.nodes)-1))
+}
+
+func (n *boolNode) match(item Sentence) bool {
+	for _, child := range n.nodes {
+		if child.match(item) != n.and {
+			return !n.and
+		}
+	}
+	return n.and
+}
+
+func (n *boolNode) cli(parent int) string {
+	prec, sep := precOr, " or "
+	if n.and {
+		prec, sep = precAnd, " and "
+	}
+	parts := make([]string, len(n.nodes))
+	for i, child := range n.nodes {
+		parts[i] = child.cli(prec)
+	}
+	s := strings.Join(parts, sep)
+	if parent > prec {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+// QuoteValue quotes a value for the CLI when it contains anything besides
+// letters, digits and -_.:/*@,+, escaping ", \, $ and control characters
+func QuoteValue(value string) string {
+	if value != "" && strings.IndexFunc(value, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_.:/*@,+", r))
+	}) < 0 {
+		return value
+	}
+	b := strings.Builder{}
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"', '\\', '$', '?':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// ParseQuery parses API query words into a Query following the RouterOS stack
+// rules: every word pushes a condition and ?# applies operations to the stack
+// (! negates the top, & and | combine the two top values, a number pushes a
+// copy of the value at that index from the top or, at the end of the word,
+// replaces the stack with it, and . pushes a copy of the top unless it follows
+// a number). The values left on the stack are combined with and.
+func ParseQuery(words ...string) (Query, error) {
+	stack, err := rosquery.Stack(words, rosquery.Algebra[Query]{
+		Cond: func(c rosquery.Condition) Query {
+			return Query{node: &condNode{op: c.Op, name: c.Name, value: c.Value}}
+		},
+		Not: func(q Query) Query {
+			if n, ok := q.node.(*notNode); ok {
+				return Query{node: n.node}
+			}
+			return Not(q)
+		},
+		And: func(a, b Query) Query { return And(a, b) },
+		Or:  func(a, b Query) Query { return Or(a, b) },
+	})
+	if err != nil {
+		return Query{}, err
+	}
+	return And(stack...), nil
+}
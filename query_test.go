@@ -0,0 +1,117 @@
+package go_routeros
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query Query
+		words []string
+		cli   string
+	}{
+		{
+			"Igual",
+			Where("name").Eq("ether1"),
+			[]string{"?name=ether1"},
+			"name=ether1",
+		},
+		{
+			"OR",
+			Where("name").Eq("x").Or(Where("disabled").Eq("yes")),
+			[]string{"?name=x", "?disabled=yes", "?#|"},
+			"name=x or disabled=yes",
+		},
+		{
+			"OR encadeado",
+			Where("type").Eq("ether").Or(Where("type").Eq("vlan")).Or(Where("type").Eq("wlan")),
+			[]string{"?type=ether", "?type=vlan", "?type=wlan", "?#||"},
+			"type=ether or type=vlan or type=wlan",
+		},
+		{
+			"OR agrupado e AND externo",
+			Where("list").Eq("aviso").Or(Where("list").Eq("block")).And(Where("disabled").Eq("no")),
+			[]string{"?list=aviso", "?list=block", "?#|", "?disabled=no", "?#&"},
+			"(list=aviso or list=block) and disabled=no",
+		},
+		{
+			"AND dentro de OR",
+			Or(Where("a").Eq("1").And(Where("b").Eq("2")), Where("c").Eq("3")),
+			[]string{"?a=1", "?b=2", "?#&", "?c=3", "?#|"},
+			"a=1 and b=2 or c=3",
+		},
+		{
+			"Has, Missing, Lt e Gt",
+			And(Where("comment").Has(), Where("dynamic").Missing(), Where("mtu").Lt("1500"), Where("rx-byte").Gt("0")),
+			[]string{"?comment", "?-dynamic", "?<mtu=1500", "?>rx-byte=0", "?#&&&"},
+			"comment and !dynamic and mtu<1500 and rx-byte>0",
+		},
+		{
+			"NOT",
+			Where("name").Eq("ether1").Not().And(Where("type").Eq("ether").Or(Where("type").Eq("vlan")).Not()),
+			[]string{"?name=ether1", "?#!", "?type=ether", "?type=vlan", "?#|", "?#!", "?#&"},
+			"name!=ether1 and !(type=ether or type=vlan)",
+		},
+		{
+			"Negação da consulta vazia",
+			Not(Query{}),
+			[]string{"?.id", "?-.id", "?#&"},
+			"false",
+		},
+		{
+			"Valor com caracteres especiais",
+			Where("comment").Eq(`say "hi" $x`),
+			[]string{`?comment=say "hi" $x`},
+			`comment="say \"hi\" \$x"`,
+		},
+		{
+			"Vazio",
+			And(),
+			nil,
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if words := tt.query.Words(); !reflect.DeepEqual(words, tt.words) {
+				t.Errorf("expected words: %v, got: %v", tt.words, words)
+			}
+			if cli := tt.query.String(); cli != tt.cli {
+				t.Errorf("expected: %s, got: %s", tt.cli, cli)
+			}
+		})
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	item := parseSentence([]string{"!re", "=.id=*1", "=name=ether1", "=mtu=1500", "=disabled=false"})
+	tests := []struct {
+		name     string
+		query    Query
+		expected bool
+	}{
+		{"Vazia", Query{}, true},
+		{"Igual", Where("name").Eq("ether1"), true},
+		{"Diferente", Where("name").Eq("ether2"), false},
+		{"Menor numérico", Where("mtu").Lt("9000"), true},
+		{"Maior numérico", Where("mtu").Gt("576"), true},
+		{"Possui", Where("mtu").Has(), true},
+		{"Ausente", Where("comment").Missing(), true},
+		{"Negação", Where("disabled").Eq("false").Not(), false},
+		{"OR", Where("name").Eq("x").Or(Where("mtu").Eq("1500")), true},
+		{"AND", Where("name").Eq("ether1").And(Where("comment").Has()), false},
+		{"Negação da vazia", Not(Query{}), false},
+		{"Dupla negação da vazia", Not(Query{}).Not(), true},
+		{"Negação da vazia com OR", Not(Query{}).Or(Where("name").Eq("ether1")), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.Match(item); got != tt.expected {
+				t.Errorf("expected: %v, got: %v", tt.expected, got)
+			}
+		})
+	}
+}
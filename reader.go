@@ -1,10 +1,9 @@
 package go_routeros
 
 import (
-	"fmt"
 	"io"
-	"strings"
 	"sync"
+	"time"
 )
 
 var bufPool = sync.Pool{
@@ -39,25 +38,28 @@ func (c *Client) readWord() (string, error) {
 }
 
 // readSentence reads a sentence (list of words) from the connection
-func (c *Client) readSentence() (map[string]string, error) {
-	sentence := make(map[string]string)
+func (c *Client) readSentence() (Sentence, error) {
+	if c.readTimeout > 0 {
+		if d, ok := c.conn.(interface{ SetReadDeadline(time.Time) error }); ok {
+			// the timeout starts with the first byte, idle connections are fine
+			if _, err := c.reader.Peek(1); err != nil {
+				return Sentence{}, err
+			}
+			_ = d.SetReadDeadline(time.Now().Add(c.readTimeout))
+			defer d.SetReadDeadline(time.Time{})
+		}
+	}
+	var words []string
 	for {
 		word, err := c.readWord()
-		if c.debug {
-			fmt.Printf("DEBUG READER: %s\n", word)
-		}
 		if err != nil {
-			return nil, err
+			return Sentence{}, err
 		}
 		if word == "" {
 			break
 		}
-		if strings.HasPrefix(word, "!") {
-			sentence["!type"] = word
-		} else if strings.Contains(word, "=") {
-			parts := strings.SplitN(strings.TrimLeft(word, "="), "=", 2)
-			sentence[parts[0]] = parts[1]
-		}
+		words = append(words, word)
 	}
-	return sentence, nil
+	c.logWords("read sentence", words)
+	return parseSentence(words), nil
 }
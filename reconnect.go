@@ -0,0 +1,189 @@
+package go_routeros
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrReconnecting is returned when a command is sent while the client is
+// reconnecting to the router
+var ErrReconnecting = errors.New("reconnecting to router os")
+
+// Backoff controls the delay between reconnection attempts
+type Backoff struct {
+	// Min delay before the first attempt, defaults to 1s
+	Min time.Duration
+	// Max delay between attempts, defaults to 1m
+	Max time.Duration
+	// Factor multiplies the delay after each failed attempt, defaults to 2
+	Factor float64
+	// MaxAttempts gives up after this many attempts, zero retries forever
+	MaxAttempts int
+}
+
+// delay returns how long to wait before the given attempt, starting at zero
+func (b Backoff) delay(attempt int) time.Duration {
+	min, max, factor := b.Min, b.Max, b.Factor
+	if min <= 0 {
+		min = time.Second
+	}
+	if max <= 0 {
+		max = time.Minute
+	}
+	if factor < 1 {
+		factor = 2
+	}
+	d := float64(min)
+	for i := 0; i < attempt && d < float64(max); i++ {
+		d *= factor
+	}
+	if d > float64(max) {
+		return max
+	}
+	return time.Duration(d)
+}
+
+// EnableReconnect makes the client redial, log in again and resubscribe the
+// commands sent with CommandOptions.Resubscribe when the connection is lost.
+// Commands without Resubscribe still fail with a !fatal response. It only
+// applies to clients created by one of the Dial functions.
+func (c *Client) EnableReconnect(backoff Backoff) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.backoff = &backoff
+}
+
+// DisableReconnect turns off automatic reconnection
+func (c *Client) DisableReconnect() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.backoff = nil
+}
+
+// reconnect is called by the read loop when the connection was lost. It
+// returns false when reconnection is disabled, gave up or the client was closed.
+func (c *Client) reconnect(cause error) bool {
+	c.lock.Lock()
+	backoff, dial := c.backoff, c.dial
+	username, password := c.username, c.password
+	if backoff == nil || dial == nil {
+		c.lock.Unlock()
+		return false
+	}
+	c.isConnected = false
+	c.reconnecting = true
+	lost := fmt.Errorf("connection lost: %w", cause)
+	var resubscribe []*Request
+	for k, req := range c.responses {
+		delete(c.responses, k)
+		if req.resubscribe && !req.cancelled.Load() {
+			resubscribe = append(resubscribe, req)
+			continue
+		}
+		req.close(fatalResponse(newConnectionError(lost)))
+	}
+	conn := c.conn
+	c.lock.Unlock()
+	_ = conn.Close()
+
+	logger := c.log()
+	if logger != nil {
+		logger.Warn("connection lost, reconnecting", "error", cause, "resubscribe", len(resubscribe))
+	}
+
+	giveUp := func(err error) bool {
+		c.lock.Lock()
+		c.reconnecting = false
+		c.lock.Unlock()
+		for _, req := range resubscribe {
+			req.close(fatalResponse(newConnectionError(err)))
+		}
+		return false
+	}
+
+	var loginCtx context.Context
+	var cancelLogin context.CancelFunc
+	for attempt := 0; ; attempt++ {
+		if backoff.MaxAttempts > 0 && attempt >= backoff.MaxAttempts {
+			return giveUp(lost)
+		}
+		select {
+		case <-c.ctx.Done():
+			return giveUp(ErrClosed)
+		case <-time.After(backoff.delay(attempt)):
+		}
+
+		conn, err := dial(c.ctx)
+		if err != nil {
+			if logger != nil {
+				logger.Warn("reconnect failed", "attempt", attempt+1, "error", err)
+			}
+			continue
+		}
+		c.writeLock.Lock()
+		c.lock.Lock()
+		c.conn = conn
+		c.reader = bufio.NewReader(conn)
+		c.lock.Unlock()
+		c.writeLock.Unlock()
+		c.logLock.Lock()
+		c.remote = remoteAddr(conn)
+		c.logLock.Unlock()
+
+		ctx, cancel := c.withLoginTimeout(c.ctx)
+		if err := c.login(ctx, username, password); err != nil {
+			cancel()
+			if logger != nil {
+				logger.Warn("login after reconnect failed", "attempt", attempt+1, "error", err)
+			}
+			_ = conn.Close()
+			// retrying rejected credentials would only lock the user out
+			if errors.Is(err, ErrInvalidCredentials) || errors.Is(err, ErrTooManyLoginAttempts) {
+				return giveUp(err)
+			}
+			continue
+		}
+		if c.ctx.Err() != nil {
+			cancel()
+			_ = conn.Close()
+			return giveUp(ErrClosed)
+		}
+		loginCtx, cancelLogin = ctx, cancel
+		break
+	}
+
+	for _, req := range resubscribe {
+		if req.resume != nil {
+			req.words = append([]string{req.words[0]}, req.resume()...)
+		}
+	}
+	c.lock.Lock()
+	c.isConnected = true
+	c.reconnecting = false
+	sentences := make([][]string, 0, len(resubscribe))
+	for _, req := range resubscribe {
+		req.tag = c.nextTag()
+		c.responses[req.tag] = req
+		sentences = append(sentences, append(req.words[:len(req.words):len(req.words)], fmt.Sprintf(".tag=%d", req.tag)))
+	}
+	c.lock.Unlock()
+
+	if logger != nil {
+		logger.Info("reconnected", "resubscribed", len(resubscribe))
+	}
+	for i, req := range resubscribe {
+		req.pushMarker(Response{Type: TypeResynced, Sentence: Sentence{Word: TypeResynced}})
+		// a failed write is noticed by the read loop, which reconnects again
+		_ = c.writeSentence(sentences[i])
+	}
+	// the router may have been upgraded, its info is read again once the read
+	// loop resumes, within the login timeout
+	go func() {
+		defer cancelLogin()
+		c.readRouterInfo(loginCtx)
+	}()
+	return true
+}
@@ -0,0 +1,125 @@
+package go_routeros
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/leandrose/go-routeros/routerostest"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		backoff  Backoff
+		attempt  int
+		expected time.Duration
+	}{
+		{"Default first attempt", Backoff{}, 0, time.Second},
+		{"Default third attempt", Backoff{}, 2, 4 * time.Second},
+		{"Default capped", Backoff{}, 20, time.Minute},
+		{"Custom", Backoff{Min: 100 * time.Millisecond, Max: time.Second, Factor: 3}, 2, 900 * time.Millisecond},
+		{"Custom capped", Backoff{Min: 100 * time.Millisecond, Max: time.Second, Factor: 3}, 3, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := tt.backoff.delay(tt.attempt); d != tt.expected {
+				t.Errorf("expected: %s, got: %s", tt.expected, d)
+			}
+		})
+	}
+}
+
+func TestReconnectResubscribes(t *testing.T) {
+	server := routerostest.NewServer()
+	server.Reply("/interface/listen", routerostest.Re("=name=ether1"), routerostest.Block())
+	server.Reply("/ip/address/print", routerostest.Block())
+	addr, err := server.Listen()
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer server.Close()
+
+	client, err := DialContext(context.Background(), addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+	client.EnableReconnect(Backoff{Min: 10 * time.Millisecond, Max: 50 * time.Millisecond})
+	if err := client.Login("admin", ""); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	listen, err := client.SendCommandWithOptions(context.Background(), CommandOptions{Resubscribe: true}, "/interface/listen")
+	if err != nil {
+		t.Fatalf("send listen: %v", err)
+	}
+	print, err := client.SendCommandContext(context.Background(), "/ip/address/print")
+	if err != nil {
+		t.Fatalf("send print: %v", err)
+	}
+	if response := <-listen.Responses(); response.Type != "!re" {
+		t.Fatalf("expected !re, got: %s", response.Type)
+	}
+
+	server.CloseConnections()
+
+	var last Response
+	for response := range print.Responses() {
+		last = response
+	}
+	if last.Type != "!fatal" {
+		t.Errorf("expected the print to fail, got: %s", last.Type)
+	}
+
+	expected := []string{TypeResynced, "!re"}
+	for _, typ := range expected {
+		select {
+		case response := <-listen.Responses():
+			if response.Type != typ {
+				t.Fatalf("expected %s, got: %s", typ, response.Type)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for %s", typ)
+		}
+	}
+	if !client.IsConnected() {
+		t.Errorf("expected the client to be connected")
+	}
+}
+
+func TestReconnectRefreshesRouterInfo(t *testing.T) {
+	server := routerostest.NewServer()
+	server.Reply("/system/resource/print", routerostest.Re("=version=7.12.1 (stable)"))
+	addr, err := server.Listen()
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer server.Close()
+
+	client, err := DialContext(context.Background(), addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+	client.EnableReconnect(Backoff{Min: 10 * time.Millisecond, Max: 50 * time.Millisecond})
+	if err := client.Login("admin", ""); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if version := client.RouterInfo().Version; version != "7.12.1 (stable)" {
+		t.Fatalf("unexpected version: %s", version)
+	}
+
+	// the router was upgraded while the connection was down
+	server.Reply("/system/resource/print", routerostest.Re("=version=7.14 (stable)"))
+	server.CloseConnections()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for client.RouterInfo().Version != "7.14 (stable)" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if version := client.RouterInfo().Version; version != "7.14 (stable)" {
+		t.Errorf("expected the version read after the reconnect, got: %s", version)
+	}
+}
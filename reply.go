@@ -0,0 +1,55 @@
+package go_routeros
+
+import "context"
+
+// Reply is the result of a command executed with Run
+type Reply struct {
+	// Re the !re sentences in the order they were received
+	Re []Sentence
+	// Done the final !done sentence, carries attributes such as ret
+	Done Sentence
+}
+
+// Ret returns the ret attribute of the !done sentence, e.g. the .id of an added item
+func (r *Reply) Ret() string {
+	return r.Done.Value("ret")
+}
+
+// Run sends a command and waits for it to finish, collecting every !re sentence.
+// A !trap or !fatal reply is returned as error. When ctx is done the command is
+// cancelled and ctx.Err() is returned.
+func (c *Client) Run(ctx context.Context, cmd string, args ...string) (*Reply, error) {
+	req, err := c.SendCommandContext(ctx, cmd, args...)
+	if err != nil {
+		return nil, err
+	}
+	return collectReply(ctx, req)
+}
+
+// collectReply waits for the replies of req
+func collectReply(ctx context.Context, req *Request) (*Reply, error) {
+	reply := &Reply{}
+	for {
+		select {
+		case <-ctx.Done():
+			_ = req.Cancel()
+			return nil, ctx.Err()
+		case response, ok := <-req.Responses():
+			if !ok {
+				if err := req.Err(); err != nil {
+					return nil, err
+				}
+				return nil, ErrClosed
+			}
+			switch response.Type {
+			case "!re":
+				reply.Re = append(reply.Re, response.Sentence)
+			case "!done", "!empty":
+				reply.Done = response.Sentence
+				return reply, nil
+			case "!trap", "!fatal":
+				return nil, response.Err
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package go_routeros
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/leandrose/go-routeros/routerostest"
+)
+
+func TestRun(t *testing.T) {
+	server := routerostest.NewServer()
+	server.Reply("/ip/address/print",
+		routerostest.Re("=.id=*1", "=address=10.0.0.1/24"),
+		routerostest.Re("=.id=*2", "=address=10.0.0.2/24"),
+	)
+	server.Reply("/ip/address/add", routerostest.Done("=ret=*3"))
+	server.Reply("/ip/address/remove", routerostest.Trap(0, "no such item"))
+	client := newTestClient(t, server)
+	ctx := context.Background()
+
+	reply, err := client.Run(ctx, "/ip/address/print")
+	if err != nil {
+		t.Fatalf("print: %v", err)
+	}
+	if len(reply.Re) != 2 || reply.Re[1].Value("address") != "10.0.0.2/24" {
+		t.Errorf("unexpected rows: %v", reply.Re)
+	}
+
+	reply, err = client.Run(ctx, "/ip/address/add", "=address=10.0.0.3/24")
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if reply.Ret() != "*3" {
+		t.Errorf("expected ret *3, got: %s", reply.Ret())
+	}
+
+	_, err = client.Run(ctx, "/ip/address/remove", "=.id=*9")
+	if !errors.Is(err, ErrNoSuchItem) {
+		t.Fatalf("expected ErrNoSuchItem, got: %v", err)
+	}
+	var rosErr *RouterOSError
+	if !errors.As(err, &rosErr) || rosErr.Command != "/ip/address/remove" || rosErr.Category != CategoryMissingItem {
+		t.Errorf("unexpected error: %+v", rosErr)
+	}
+
+	_, err = client.Run(ctx, "/nothing/print")
+	if err == nil {
+		t.Errorf("expected an error for an unknown command")
+	}
+}
+
+func TestRunContextDeadline(t *testing.T) {
+	server := routerostest.NewServer()
+	server.Reply("/tool/torch", routerostest.Block())
+	client := newTestClient(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.Run(ctx, "/tool/torch", "=interface=ether1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got: %v", err)
+	}
+}
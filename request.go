@@ -0,0 +1,383 @@
+package go_routeros
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrOverflow is reported by a command using CancelOnOverflow when its consumer
+// falls behind
+var ErrOverflow = errors.New("response buffer overflow")
+
+// ErrInvalidDelivery is returned for a DropOnOverflow or CancelOnOverflow
+// without room for a reply
+var ErrInvalidDelivery = errors.New("the delivery size must be positive")
+
+// ErrCancelled is reported by a command cancelled with Request.Cancel
+var ErrCancelled = errors.New("command cancelled")
+
+// DeliveryMode how replies are queued when the consumer is slower than the router
+type DeliveryMode int
+
+const (
+	// DeliverDefault uses the Delivery of the client, set WithDelivery
+	DeliverDefault DeliveryMode = iota
+	// DeliverUnbounded queues every reply, memory grows with the backlog
+	DeliverUnbounded
+	// DeliverDrop keeps up to Size pending replies and drops the newest ones
+	DeliverDrop
+	// DeliverCancel keeps up to Size pending replies and cancels the command on overflow
+	DeliverCancel
+)
+
+// Delivery strategy used to hand replies of a command to its consumer. The
+// read loop never waits for the consumer, whatever the strategy. The zero
+// value is DeliverDefault.
+type Delivery struct {
+	Mode DeliveryMode
+	// Size maximum pending replies for DeliverDrop and DeliverCancel
+	Size int
+}
+
+// Unbounded queues every reply until it is consumed
+func Unbounded() Delivery {
+	return Delivery{Mode: DeliverUnbounded}
+}
+
+// DropOnOverflow keeps up to size pending replies, the surplus is dropped and
+// counted in Request.Dropped
+func DropOnOverflow(size int) Delivery {
+	return Delivery{Mode: DeliverDrop, Size: size}
+}
+
+// CancelOnOverflow keeps up to size pending replies, the command is cancelled
+// with ErrOverflow when the buffer is full
+func CancelOnOverflow(size int) Delivery {
+	return Delivery{Mode: DeliverCancel, Size: size}
+}
+
+// validate rejects a bounded delivery without room for a reply
+func (d Delivery) validate() error {
+	if (d.Mode == DeliverDrop || d.Mode == DeliverCancel) && d.Size <= 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidDelivery, d.Size)
+	}
+	return nil
+}
+
+// CommandOptions options of a single command
+type CommandOptions struct {
+	// Delivery strategy for the replies, the zero value uses the one of the
+	// client, Unbounded unless set WithDelivery. Unbounded() overrides a
+	// bounded delivery of the client.
+	Delivery Delivery
+	// Resubscribe issues the command again after the client reconnected, for
+	// long-lived commands such as listen or follow prints. A TypeResynced
+	// response is delivered before the replies of the new subscription.
+	Resubscribe bool
+	// Resume returns the arguments of the command issued again by Resubscribe,
+	// e.g. to continue after the last item seen. The original arguments are
+	// used when it is nil.
+	Resume func() []string
+}
+
+// Request is a command sent to RouterOS whose replies are still being received
+type Request struct {
+	client      *Client
+	tag         int
+	words       []string
+	resubscribe bool
+	resume      func() []string
+	delivery    Delivery
+	ch          chan Response
+	done        chan struct{}
+	abort       chan struct{}
+	notify      chan struct{}
+
+	mu        sync.Mutex
+	queue     []Response
+	inflight  int
+	closed    bool
+	err       error
+	cancelled atomic.Bool
+	dropped   atomic.Uint64
+
+	cancelOnce sync.Once
+	abortOnce  sync.Once
+	doneOnce   sync.Once
+}
+
+func newRequest(c *Client, tag int, words []string, opts CommandOptions) *Request {
+	r := &Request{
+		client:      c,
+		tag:         tag,
+		words:       words,
+		resubscribe: opts.Resubscribe,
+		resume:      opts.Resume,
+		delivery:    opts.Delivery,
+		ch:          make(chan Response),
+		done:        make(chan struct{}),
+		abort:       make(chan struct{}),
+		notify:      make(chan struct{}, 1),
+	}
+	go r.pump()
+	return r
+}
+
+// Tag returns the .tag assigned to the command, it changes when the command
+// is issued again after a reconnect
+func (r *Request) Tag() int {
+	r.client.lock.Lock()
+	defer r.client.lock.Unlock()
+	return r.tag
+}
+
+// Responses returns the channel that receives the replies of the command.
+// The channel is closed after !done, !trap, !fatal or !empty, or as soon as
+// the command is cancelled.
+func (r *Request) Responses() <-chan Response {
+	return r.ch
+}
+
+// Done returns a channel that is closed when the command has finished
+func (r *Request) Done() <-chan struct{} {
+	return r.done
+}
+
+// Dropped returns how many replies were dropped by DropOnOverflow
+func (r *Request) Dropped() uint64 {
+	return r.dropped.Load()
+}
+
+// Err returns why the command was cancelled, or nil
+func (r *Request) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Cancel stops the command by sending /cancel for its tag and closes the
+// response channel. Replies received after the cancellation, including the
+// resulting !trap, are drained and discarded.
+func (r *Request) Cancel() error {
+	return r.cancel(ErrCancelled)
+}
+
+func (r *Request) cancel(cause error) error {
+	r.setErr(cause)
+	r.abortOnce.Do(func() {
+		close(r.abort)
+	})
+	return r.sendCancel()
+}
+
+// sendCancel sends /cancel once, replies received from now on are discarded
+func (r *Request) sendCancel() error {
+	var err error
+	r.cancelOnce.Do(func() {
+		r.cancelled.Store(true)
+		select {
+		case <-r.done:
+			return
+		default:
+		}
+		if err = r.client.cancelTag(r.Tag()); err != nil {
+			// the router will never acknowledge it, release the command now
+			r.client.finish(r, nil)
+		}
+	})
+	return err
+}
+
+func (r *Request) setErr(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// push queues a reply for the consumer without blocking
+func (r *Request) push(response Response) {
+	if r.cancelled.Load() {
+		return
+	}
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	bounded := r.delivery.Mode == DeliverDrop || r.delivery.Mode == DeliverCancel
+	if bounded && len(r.queue)+r.inflight >= r.delivery.Size {
+		switch r.delivery.Mode {
+		case DeliverDrop:
+			r.mu.Unlock()
+			r.dropped.Add(1)
+			return
+		case DeliverCancel:
+			if r.err == nil {
+				r.err = ErrOverflow
+			}
+			r.queue = append(r.queue, *fatalResponse(ErrOverflow))
+			r.closed = true
+			r.mu.Unlock()
+			r.signal()
+			// the read loop must not wait for the write
+			go func() {
+				_ = r.sendCancel()
+			}()
+			return
+		}
+	}
+	r.queue = append(r.queue, response)
+	r.mu.Unlock()
+	r.signal()
+}
+
+// pushMarker queues a response of the client, such as TypeResynced, past the
+// bound of the delivery like the final reply, so it is neither dropped nor
+// cancels the command
+func (r *Request) pushMarker(response Response) {
+	if r.cancelled.Load() {
+		return
+	}
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	r.queue = append(r.queue, response)
+	r.mu.Unlock()
+	r.signal()
+}
+
+// close stops accepting replies, final is delivered last when it is not nil
+func (r *Request) close(final *Response) {
+	r.mu.Lock()
+	if !r.closed {
+		if final != nil && !r.cancelled.Load() {
+			r.queue = append(r.queue, *final)
+		}
+		r.closed = true
+	}
+	r.mu.Unlock()
+	r.doneOnce.Do(func() {
+		close(r.done)
+	})
+	r.signal()
+}
+
+func (r *Request) signal() {
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pump hands the queued replies to the consumer
+func (r *Request) pump() {
+	defer close(r.ch)
+	for {
+		r.mu.Lock()
+		for len(r.queue) == 0 && !r.closed {
+			r.mu.Unlock()
+			select {
+			case <-r.notify:
+			case <-r.abort:
+				return
+			}
+			r.mu.Lock()
+		}
+		if len(r.queue) == 0 {
+			r.mu.Unlock()
+			return
+		}
+		response := r.queue[0]
+		r.queue[0] = Response{}
+		r.queue = r.queue[1:]
+		r.inflight = 1
+		r.mu.Unlock()
+
+		select {
+		case r.ch <- response:
+		case <-r.abort:
+			return
+		}
+		r.mu.Lock()
+		r.inflight = 0
+		r.mu.Unlock()
+	}
+}
+
+// SendCommandContext sends a command to RouterOS and returns a handle to receive
+// its responses. When ctx is done the command is cancelled with /cancel.
+func (c *Client) SendCommandContext(ctx context.Context, cmd string, args ...string) (*Request, error) {
+	return c.SendCommandWithOptions(ctx, CommandOptions{}, cmd, args...)
+}
+
+// SendCommandWithOptions is like SendCommandContext with options for the command
+func (c *Client) SendCommandWithOptions(ctx context.Context, opts CommandOptions, cmd string, args ...string) (*Request, error) {
+	words := append([]string{cmd}, args...)
+
+	c.lock.Lock()
+	if c.reconnecting {
+		c.lock.Unlock()
+		return nil, ErrReconnecting
+	}
+	if opts.Delivery.Mode == DeliverDefault {
+		opts.Delivery = c.delivery
+	}
+	if opts.Delivery.Mode == DeliverDefault {
+		opts.Delivery = Unbounded()
+	}
+	if err := opts.Delivery.validate(); err != nil {
+		c.lock.Unlock()
+		return nil, err
+	}
+	req := newRequest(c, c.nextTag(), words, opts)
+	c.responses[req.tag] = req
+	fullCmd := append(words[:len(words):len(words)], fmt.Sprintf(".tag=%d", req.tag))
+	c.lock.Unlock()
+
+	if err := c.writeSentence(fullCmd); err != nil {
+		c.finish(req, nil)
+		return nil, err
+	}
+
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				_ = req.cancel(ctx.Err())
+			case <-req.done:
+			}
+		}()
+	}
+
+	return req, nil
+}
+
+// cancelTag sends /cancel for the command identified by tag
+func (c *Client) cancelTag(tag int) error {
+	c.lock.Lock()
+	id := c.nextTag()
+	c.lock.Unlock()
+
+	return c.writeSentence([]string{
+		"/cancel",
+		fmt.Sprintf("=tag=%d", tag),
+		fmt.Sprintf(".tag=%d", id),
+	})
+}
+
+// finish removes the request from the client, final is its last reply
+func (c *Client) finish(req *Request, final *Response) {
+	c.lock.Lock()
+	if c.responses[req.tag] == req {
+		delete(c.responses, req.tag)
+	}
+	c.lock.Unlock()
+	req.close(final)
+}
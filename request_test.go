@@ -0,0 +1,195 @@
+package go_routeros
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/leandrose/go-routeros/routerostest"
+)
+
+type discardConn struct{}
+
+func (discardConn) Read([]byte) (int, error)    { return 0, io.EOF }
+func (discardConn) Write(p []byte) (int, error) { return len(p), nil }
+func (discardConn) Close() error                { return nil }
+
+func newTestRequest(delivery Delivery) *Request {
+	c := &Client{conn: discardConn{}, responses: make(map[int]*Request)}
+	req := newRequest(c, 1, nil, CommandOptions{Delivery: delivery})
+	c.responses[req.tag] = req
+	return req
+}
+
+func reply(word string) Response {
+	return Response{Type: word, Sentence: Sentence{Word: word}}
+}
+
+func TestRequestDelivery(t *testing.T) {
+	tests := []struct {
+		name     string
+		delivery Delivery
+		expected []string
+		dropped  uint64
+		err      error
+	}{
+		{"Unbounded", Unbounded(), []string{"!re", "!re", "!re", "!re", "!done"}, 0, nil},
+		{"Drop", DropOnOverflow(2), []string{"!re", "!re", "!done"}, 2, nil},
+		{"Cancel", CancelOnOverflow(2), []string{"!re", "!re", "!fatal"}, 0, ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newTestRequest(tt.delivery)
+			for i := 0; i < 4; i++ {
+				req.push(reply("!re"))
+			}
+			done := reply("!done")
+			req.client.finish(req, &done)
+
+			var got []string
+			for response := range req.Responses() {
+				got = append(got, response.Type)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected: %v, got: %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("expected: %v, got: %v", tt.expected, got)
+				}
+			}
+			if req.Dropped() != tt.dropped {
+				t.Errorf("expected %d dropped, got: %d", tt.dropped, req.Dropped())
+			}
+			if !errors.Is(req.Err(), tt.err) {
+				t.Errorf("expected error %v, got: %v", tt.err, req.Err())
+			}
+		})
+	}
+}
+
+func TestRequestResyncedMarker(t *testing.T) {
+	tests := []struct {
+		name     string
+		delivery Delivery
+	}{
+		{"Drop", DropOnOverflow(1)},
+		{"Cancel", CancelOnOverflow(1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newTestRequest(tt.delivery)
+			req.push(reply("!re"))
+			req.pushMarker(reply(TypeResynced))
+			done := reply("!done")
+			req.client.finish(req, &done)
+
+			var got []string
+			for response := range req.Responses() {
+				got = append(got, response.Type)
+			}
+			if expected := []string{"!re", TypeResynced, "!done"}; !reflect.DeepEqual(got, expected) {
+				t.Errorf("expected: %v, got: %v", expected, got)
+			}
+			if req.Dropped() != 0 || req.Err() != nil {
+				t.Errorf("expected no drop nor error, got: %d %v", req.Dropped(), req.Err())
+			}
+		})
+	}
+}
+
+func TestRequestCancelClosesChannel(t *testing.T) {
+	req := newTestRequest(Unbounded())
+	req.push(reply("!re"))
+	if err := req.Cancel(); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	for range req.Responses() {
+	}
+	if !errors.Is(req.Err(), ErrCancelled) {
+		t.Errorf("expected ErrCancelled, got: %v", req.Err())
+	}
+	// the read loop drains the remaining replies
+	req.push(reply("!re"))
+	trap := reply("!trap")
+	req.client.finish(req, &trap)
+	<-req.Done()
+}
+
+func TestSendCommandContextCancel(t *testing.T) {
+	server := routerostest.NewServer()
+	server.Reply("/interface/listen", routerostest.Re("=name=ether1"), routerostest.Block())
+	client := newTestClient(t, server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := client.SendCommandContext(ctx, "/interface/listen")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	if response := <-req.Responses(); response.Type != "!re" {
+		t.Fatalf("expected !re, got: %s", response.Type)
+	}
+
+	cancel()
+	for range req.Responses() {
+	}
+	select {
+	case <-req.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("the cancellation was not acknowledged")
+	}
+	if !errors.Is(req.Err(), context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", req.Err())
+	}
+
+	var cancelled bool
+	listenTag := strconv.Itoa(req.Tag())
+	for _, cmd := range server.Received() {
+		if tag, _ := cmd.Attr("tag"); cmd.Path == "/cancel" && tag == listenTag {
+			cancelled = true
+		}
+	}
+	if !cancelled {
+		t.Errorf("expected /cancel =tag=%s, got: %v", listenTag, server.Received())
+	}
+
+	// the connection is still usable
+	server.Reply("/system/identity/print", routerostest.Re("=name=router"))
+	if _, err := client.Run(context.Background(), "/system/identity/print"); err != nil {
+		t.Errorf("run after cancel: %v", err)
+	}
+}
+
+func TestCommandDelivery(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   Delivery
+		command  Delivery
+		expected Delivery
+		err      error
+	}{
+		{"Padrão do cliente", Delivery{}, Delivery{}, Unbounded(), nil},
+		{"Entrega do cliente", DropOnOverflow(10), Delivery{}, DropOnOverflow(10), nil},
+		{"Comando ilimitado em cliente limitado", CancelOnOverflow(10), Unbounded(), Unbounded(), nil},
+		{"Entrega do comando", Unbounded(), DropOnOverflow(5), DropOnOverflow(5), nil},
+		{"Descarte sem espaço", Delivery{}, DropOnOverflow(0), Delivery{}, ErrInvalidDelivery},
+		{"Cancelamento sem espaço", CancelOnOverflow(-1), Delivery{}, Delivery{}, ErrInvalidDelivery},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(discardConn{}, WithDelivery(tt.client))
+			req, err := client.SendCommandWithOptions(context.Background(), CommandOptions{Delivery: tt.command}, "/interface/listen")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got: %v", tt.err, err)
+			}
+			if err == nil && req.delivery != tt.expected {
+				t.Errorf("expected: %+v, got: %+v", tt.expected, req.delivery)
+			}
+		})
+	}
+}
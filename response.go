@@ -1,10 +1,33 @@
 package go_routeros
 
+// TypeResynced is the Type of the synthetic response delivered to commands sent
+// with CommandOptions.Resubscribe once they were issued again after a reconnect
+const TypeResynced = "!resynced"
+
 type Response struct {
 	// Type response type returned by RouterOS: !re, !empty, !done, !trap, or !fatal
 	Type string
-	// Data response data returned by RouterOS
+	// Sentence the reply sentence as received from RouterOS
+	Sentence Sentence
+	// Data response attributes keyed by name, without the leading "="
+	//
+	// Deprecated: repeated attributes are collapsed, use Sentence instead.
 	Data map[string]string
 	// Err an error occurred, returned by RouterOS in a !trap or !fatal response
 	Err error
 }
+
+// fatalResponse builds a synthetic !fatal response carrying err
+func fatalResponse(err error) *Response {
+	return &Response{
+		Type: "!fatal",
+		Sentence: Sentence{
+			Word: "!fatal",
+			List: []Pair{{Key: "message", Value: err.Error()}},
+		},
+		Data: map[string]string{
+			"message": err.Error(),
+		},
+		Err: err,
+	}
+}
@@ -0,0 +1,281 @@
+package roscert
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// keyBits the size of the generated RSA keys, RouterOS v6 imports RSA keys only
+const keyBits = 2048
+
+// CA is a local certificate authority that signs router certificates
+type CA struct {
+	Certificate *x509.Certificate
+	Key         crypto.Signer
+}
+
+// NewCA generates a self-signed CA named name, valid for validity
+func NewCA(name string, validity time.Duration) (*CA, error) {
+	key, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return nil, fmt.Errorf("roscert: %w", err)
+	}
+	serial, err := newSerial()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, fmt.Errorf("roscert: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("roscert: %w", err)
+	}
+	return &CA{Certificate: cert, Key: key}, nil
+}
+
+// LoadCA loads a CA written by WriteFiles
+func LoadCA(certFile, keyFile string) (*CA, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("roscert: %w", err)
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("roscert: %w", err)
+	}
+	return ParseCA(certPEM, keyPEM)
+}
+
+// ParseCA parses the PEM certificate and key of a CA
+func ParseCA(certPEM, keyPEM []byte) (*CA, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, ErrNoCertificates
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("roscert: %w", err)
+	}
+	if !cert.IsCA {
+		return nil, fmt.Errorf("roscert: %s is not a CA", cert.Subject.CommonName)
+	}
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &CA{Certificate: cert, Key: key}, nil
+}
+
+// Pool returns a pool holding the CA, see NewTLSConfig
+func (ca *CA) Pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Certificate)
+	return pool
+}
+
+// CertPEM returns the certificate of the CA in PEM
+func (ca *CA) CertPEM() []byte {
+	return encodeCertificate(ca.Certificate)
+}
+
+// KeyPEM returns the unencrypted key of the CA in PEM
+func (ca *CA) KeyPEM() ([]byte, error) {
+	return encodePrivateKey(ca.Key)
+}
+
+// WriteFiles writes name.crt and name.key to dir, the key readable by the
+// owner only
+func (ca *CA) WriteFiles(dir, name string) (certFile, keyFile string, err error) {
+	keyPEM, err := ca.KeyPEM()
+	if err != nil {
+		return "", "", err
+	}
+	return writeFiles(dir, name, ca.CertPEM(), keyPEM)
+}
+
+// IssueRouter signs a certificate for the api-ssl service of a router. The
+// hosts are the IP addresses and DNS names the router is dialled with, the
+// first one is also the common name.
+func (ca *CA) IssueRouter(hosts []string, validity time.Duration) (*Issued, error) {
+	if len(hosts) == 0 {
+		return nil, errors.New("roscert: a router certificate needs at least one host")
+	}
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: hosts[0]},
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	return ca.issue(template, validity)
+}
+
+// IssueClient signs a client certificate named name
+func (ca *CA) IssueClient(name string, validity time.Duration) (*Issued, error) {
+	return ca.issue(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, validity)
+}
+
+func (ca *CA) issue(template *x509.Certificate, validity time.Duration) (*Issued, error) {
+	key, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return nil, fmt.Errorf("roscert: %w", err)
+	}
+	if template.SerialNumber, err = newSerial(); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template.NotBefore = now.Add(-time.Hour)
+	template.NotAfter = now.Add(validity)
+	if template.NotAfter.After(ca.Certificate.NotAfter) {
+		template.NotAfter = ca.Certificate.NotAfter
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Certificate, key.Public(), ca.Key)
+	if err != nil {
+		return nil, fmt.Errorf("roscert: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("roscert: %w", err)
+	}
+	return &Issued{Certificate: cert, Key: key, CA: ca.Certificate}, nil
+}
+
+// Issued is a certificate signed by a CA
+type Issued struct {
+	Certificate *x509.Certificate
+	Key         crypto.Signer
+	// CA the certificate of the issuer
+	CA *x509.Certificate
+}
+
+// CertPEM returns the certificate followed by the CA in PEM, the chain
+// /certificate/import expects
+func (i *Issued) CertPEM() []byte {
+	return append(encodeCertificate(i.Certificate), encodeCertificate(i.CA)...)
+}
+
+// KeyPEM returns the unencrypted key in PEM, it is imported with an empty
+// passphrase
+func (i *Issued) KeyPEM() ([]byte, error) {
+	return encodePrivateKey(i.Key)
+}
+
+// TLSCertificate returns the certificate for a tls.Config, e.g. a client
+// certificate for NewTLSConfig
+func (i *Issued) TLSCertificate() tls.Certificate {
+	return tls.Certificate{
+		Certificate: [][]byte{i.Certificate.Raw, i.CA.Raw},
+		PrivateKey:  i.Key,
+		Leaf:        i.Certificate,
+	}
+}
+
+// WriteFiles writes name.crt and name.key to dir, to be uploaded to the
+// router and imported, see the package documentation
+func (i *Issued) WriteFiles(dir, name string) (certFile, keyFile string, err error) {
+	keyPEM, err := i.KeyPEM()
+	if err != nil {
+		return "", "", err
+	}
+	return writeFiles(dir, name, i.CertPEM(), keyPEM)
+}
+
+func writeFiles(dir, name string, certPEM, keyPEM []byte) (certFile, keyFile string, err error) {
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	if err = os.WriteFile(certFile, certPEM, 0o644); err != nil {
+		return "", "", fmt.Errorf("roscert: %w", err)
+	}
+	if err = os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		return "", "", fmt.Errorf("roscert: %w", err)
+	}
+	return certFile, keyFile, nil
+}
+
+func newSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	if err != nil {
+		return nil, fmt.Errorf("roscert: %w", err)
+	}
+	return serial, nil
+}
+
+func encodeCertificate(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
+
+// encodePrivateKey writes RSA keys in PKCS #1, the form older RouterOS
+// versions import, and other keys in PKCS #8
+func encodePrivateKey(key crypto.Signer) ([]byte, error) {
+	if rsaKey, ok := key.(*rsa.PrivateKey); ok {
+		return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}), nil
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("roscert: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// parsePrivateKey parses a PEM key in PKCS #1, PKCS #8 or SEC 1
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("roscert: no private key found in the PEM data")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("roscert: %w", err)
+	}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case *ecdsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("roscert: unsupported private key %T", key)
+}
@@ -0,0 +1,104 @@
+// Package roscert loads the certificates used with API-SSL and generates a
+// local CA and router certificates to set api-ssl up with proper trust.
+//
+// A router certificate written by Issued.WriteFiles is installed with
+//
+//	/certificate/import file-name=router.crt passphrase=""
+//	/certificate/import file-name=router.key passphrase=""
+//	/ip/service/set api-ssl certificate=router.crt_0
+//
+// and the client trusts it with NewTLSConfig(ca.Pool()).
+package roscert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrNoCertificates the PEM data held no certificate
+var ErrNoCertificates = errors.New("no certificate found in the PEM data")
+
+// LoadKeyPair loads a client certificate and its key from PEM files
+func LoadKeyPair(certFile, keyFile string) (tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("roscert: %w", err)
+	}
+	return cert, nil
+}
+
+// LoadKeyPairEnv loads a client certificate and its key from the PEM held by
+// the environment variables certVar and keyVar
+func LoadKeyPairEnv(certVar, keyVar string) (tls.Certificate, error) {
+	certPEM, err := lookupEnv(certVar)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	keyPEM, err := lookupEnv(keyVar)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("roscert: %s and %s: %w", certVar, keyVar, err)
+	}
+	return cert, nil
+}
+
+// LoadCertPool loads a bundle of CA certificates from a PEM file
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("roscert: %w", err)
+	}
+	pool, err := ParseCertPool(data)
+	if err != nil {
+		return nil, fmt.Errorf("roscert: %s: %w", file, err)
+	}
+	return pool, nil
+}
+
+// LoadCertPoolEnv loads a bundle of CA certificates from the PEM held by the
+// environment variable name
+func LoadCertPoolEnv(name string) (*x509.CertPool, error) {
+	data, err := lookupEnv(name)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := ParseCertPool(data)
+	if err != nil {
+		return nil, fmt.Errorf("roscert: %s: %w", name, err)
+	}
+	return pool, nil
+}
+
+// ParseCertPool parses a bundle of PEM certificates
+func ParseCertPool(data []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, ErrNoCertificates
+	}
+	return pool, nil
+}
+
+// NewTLSConfig returns a config for go_routeros.WithTLS that trusts the
+// routers signed by roots and presents certs, e.g. from LoadKeyPair. The
+// system roots are used when roots is nil.
+func NewTLSConfig(roots *x509.CertPool, certs ...tls.Certificate) *tls.Config {
+	return &tls.Config{
+		RootCAs:      roots,
+		Certificates: certs,
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+func lookupEnv(name string) ([]byte, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return nil, fmt.Errorf("roscert: the environment variable %s is not set", name)
+	}
+	return []byte(value), nil
+}
@@ -0,0 +1,169 @@
+package roscert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	go_routeros "github.com/leandrose/go-routeros"
+	"github.com/leandrose/go-routeros/routerostest"
+)
+
+func TestRouterCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca, err := NewCA("go-routeros test CA", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("ca: %v", err)
+	}
+	caFile, caKeyFile, err := ca.WriteFiles(dir, "ca")
+	if err != nil {
+		t.Fatalf("write ca: %v", err)
+	}
+	router, err := ca.IssueRouter([]string{"127.0.0.1", "router.lan"}, 48*time.Hour)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	if !router.Certificate.NotAfter.Equal(ca.Certificate.NotAfter) {
+		t.Errorf("expected the validity to be capped by the CA, got: %s", router.Certificate.NotAfter)
+	}
+	certFile, keyFile, err := router.WriteFiles(dir, "router")
+	if err != nil {
+		t.Fatalf("write router: %v", err)
+	}
+	serverCert, err := LoadKeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("load router: %v", err)
+	}
+	server := routerostest.NewServer()
+	defer server.Close()
+	addr, err := server.ListenTLS(&tls.Config{Certificates: []tls.Certificate{serverCert}})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	dial := func(config *tls.Config) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		client, err := go_routeros.Dial(ctx, addr, go_routeros.WithTLS(config))
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		return client.Login("admin", "")
+	}
+
+	t.Run("Confia na CA carregada do arquivo", func(t *testing.T) {
+		pool, err := LoadCertPool(caFile)
+		if err != nil {
+			t.Fatalf("pool: %v", err)
+		}
+		if err = dial(NewTLSConfig(pool)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Confia na CA recarregada", func(t *testing.T) {
+		loaded, err := LoadCA(caFile, caKeyFile)
+		if err != nil {
+			t.Fatalf("load ca: %v", err)
+		}
+		if err = dial(NewTLSConfig(loaded.Pool())); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Rejeita outra CA", func(t *testing.T) {
+		other, err := NewCA("other", time.Hour)
+		if err != nil {
+			t.Fatalf("ca: %v", err)
+		}
+		var unknown x509.UnknownAuthorityError
+		if err = dial(NewTLSConfig(other.Pool())); !errors.As(err, &unknown) {
+			t.Fatalf("expected an unknown authority, got: %v", err)
+		}
+	})
+}
+
+func TestLoadEnv(t *testing.T) {
+	ca, err := NewCA("go-routeros test CA", time.Hour)
+	if err != nil {
+		t.Fatalf("ca: %v", err)
+	}
+	client, err := ca.IssueClient("controller", time.Hour)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	keyPEM, err := client.KeyPEM()
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	t.Setenv("ROUTEROS_CA", string(ca.CertPEM()))
+	t.Setenv("ROUTEROS_CERT", string(client.CertPEM()))
+	t.Setenv("ROUTEROS_KEY", string(keyPEM))
+	t.Setenv("ROUTEROS_EMPTY", "")
+
+	tests := []struct {
+		name string
+		load func() error
+		fail bool
+	}{
+		{"Par de chaves", func() error { _, err := LoadKeyPairEnv("ROUTEROS_CERT", "ROUTEROS_KEY"); return err }, false},
+		{"Bundle de CAs", func() error { _, err := LoadCertPoolEnv("ROUTEROS_CA"); return err }, false},
+		{"Variável vazia", func() error { _, err := LoadCertPoolEnv("ROUTEROS_EMPTY"); return err }, true},
+		{"Variável ausente", func() error { _, err := LoadKeyPairEnv("ROUTEROS_MISSING", "ROUTEROS_KEY"); return err }, true},
+		{"Chave trocada", func() error { _, err := LoadKeyPairEnv("ROUTEROS_CA", "ROUTEROS_KEY"); return err }, true},
+		{"Sem certificados", func() error { _, err := LoadCertPoolEnv("ROUTEROS_KEY"); return err }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.load(); (err != nil) != tt.fail {
+				t.Errorf("expected fail=%v, got: %v", tt.fail, err)
+			}
+		})
+	}
+}
+
+func TestParseCA(t *testing.T) {
+	ca, err := NewCA("go-routeros test CA", time.Hour)
+	if err != nil {
+		t.Fatalf("ca: %v", err)
+	}
+	keyPEM, err := ca.KeyPEM()
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	router, err := ca.IssueRouter([]string{"10.0.0.1"}, time.Hour)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	routerKey, _ := router.KeyPEM()
+
+	tests := []struct {
+		name    string
+		certPEM []byte
+		keyPEM  []byte
+		fail    bool
+	}{
+		{"CA válida", ca.CertPEM(), keyPEM, false},
+		{"Certificado que não é CA", router.CertPEM(), routerKey, true},
+		{"Sem certificado", nil, keyPEM, true},
+		{"Sem chave", ca.CertPEM(), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseCA(tt.certPEM, tt.keyPEM); (err != nil) != tt.fail {
+				t.Errorf("expected fail=%v, got: %v", tt.fail, err)
+			}
+		})
+	}
+
+	t.Run("Arquivo inexistente", func(t *testing.T) {
+		if _, err := LoadCA("missing.crt", "missing.key"); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected a missing file, got: %v", err)
+		}
+	})
+}
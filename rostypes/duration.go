@@ -0,0 +1,131 @@
+// Package rostypes parses and formats the RouterOS value formats: durations,
+// rates, sizes, dates, port ranges, negated values and time ranges with
+// weekdays. Parsing accepts both the v6 and v7 formats.
+package rostypes
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationUnits = []struct {
+	suffix string
+	size   time.Duration
+}{
+	{"w", 7 * 24 * time.Hour}, {"d", 24 * time.Hour}, {"h", time.Hour}, {"m", time.Minute},
+	{"s", time.Second}, {"ms", time.Millisecond}, {"us", time.Microsecond}, {"ns", time.Nanosecond},
+}
+
+// ParseDuration parses durations such as 3w2d10:05:01.5 (v6), 1w2d3h4m5s
+// (v7), 500ms, 00:00:10 or a plain number of seconds
+func ParseDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	s, negative := strings.CutPrefix(value, "-")
+	if n, err := strconv.ParseUint(s, 10, 63); err == nil {
+		d := time.Duration(n) * time.Second
+		if negative {
+			d = -d
+		}
+		return d, nil
+	}
+
+	var total time.Duration
+	for s != "" {
+		i := 0
+		for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+			i++
+		}
+		if i == 0 {
+			return 0, errors.New("invalid duration " + strconv.Quote(value))
+		}
+		if i < len(s) && s[i] == ':' {
+			d, err := parseClock(s)
+			if err != nil {
+				return 0, errors.New("invalid duration " + strconv.Quote(value))
+			}
+			total += d
+			break
+		}
+		j := i
+		for j < len(s) && s[j] >= 'a' && s[j] <= 'z' {
+			j++
+		}
+		unit := time.Duration(0)
+		for _, u := range durationUnits {
+			if u.suffix == s[i:j] {
+				unit = u.size
+			}
+		}
+		f, err := strconv.ParseFloat(s[:i], 64)
+		if unit == 0 || err != nil {
+			return 0, errors.New("invalid duration " + strconv.Quote(value))
+		}
+		total += time.Duration(f * float64(unit))
+		s = s[j:]
+	}
+	if negative {
+		total = -total
+	}
+	return total, nil
+}
+
+// parseClock parses hh:mm:ss with optional fractional seconds
+func parseClock(value string) (time.Duration, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, errors.New("invalid clock")
+	}
+	h, err1 := strconv.Atoi(parts[0])
+	m, err2 := strconv.Atoi(parts[1])
+	sec, err3 := strconv.ParseFloat(parts[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil || m > 59 || sec >= 60 {
+		return 0, errors.New("invalid clock")
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec*float64(time.Second)), nil
+}
+
+// FormatDuration formats a duration as RouterOS v7 does, e.g. 1w2d3h4m5s
+func FormatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+	b := strings.Builder{}
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+	for _, unit := range durationUnits {
+		if n := d / unit.size; n > 0 {
+			b.WriteString(strconv.FormatInt(int64(n), 10))
+			b.WriteString(unit.suffix)
+			d -= n * unit.size
+		}
+	}
+	return b.String()
+}
+
+// Duration is a time.Duration using the RouterOS text format
+type Duration time.Duration
+
+// UnmarshalText parses the RouterOS duration
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalText formats the duration as RouterOS does
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(FormatDuration(time.Duration(d))), nil
+}
+
+func (d Duration) String() string {
+	return FormatDuration(time.Duration(d))
+}
@@ -0,0 +1,246 @@
+package rostypes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{"Vazio", "", 0},
+		{"Formato v7", "5m30s", 5*time.Minute + 30*time.Second},
+		{"Relógio", "00:00:10", 10 * time.Second},
+		{"Formato v6 com semanas", "3w2d10:05:01", 23*24*time.Hour + 10*time.Hour + 5*time.Minute + time.Second},
+		{"Fração de segundo", "00:00:01.5", 1500 * time.Millisecond},
+		{"Dias e horas", "1d2h", 26 * time.Hour},
+		{"Milissegundos", "500ms", 500 * time.Millisecond},
+		{"Número em segundos", "30", 30 * time.Second},
+		{"Negativo", "-1h", -time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDuration(tt.value)
+			if err != nil || got != tt.expected {
+				t.Fatalf("expected: %s, got: %s %v", tt.expected, got, err)
+			}
+			if tt.value == "" {
+				return
+			}
+			back, err := ParseDuration(FormatDuration(got))
+			if err != nil || back != got {
+				t.Errorf("round trip of %s: got %s %v", FormatDuration(got), back, err)
+			}
+		})
+	}
+	for _, value := range []string{"1x", "h", "1:2", "aa:bb:cc"} {
+		if _, err := ParseDuration(value); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                              "0s",
+		90 * time.Second:               "1m30s",
+		8*24*time.Hour + 3*time.Hour:   "1w1d3h",
+		1500 * time.Millisecond:        "1s500ms",
+		-(2*time.Hour + 5*time.Minute): "-2h5m",
+	}
+	for d, expected := range tests {
+		if got := FormatDuration(d); got != expected {
+			t.Errorf("%s: expected: %s, got: %s", time.Duration(d), expected, got)
+		}
+	}
+}
+
+func TestSizeAndRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		parse    func(string) (uint64, error)
+		format   func(uint64) string
+		expected uint64
+		text     string
+	}{
+		{"Tamanho em bytes", "1000", parseSize, formatSize, 1000, "1000"},
+		{"Tamanho KiB", "1.5KiB", parseSize, formatSize, 1536, "1.5KiB"},
+		{"Tamanho MiB", "512MiB", parseSize, formatSize, 512 << 20, "512MiB"},
+		{"Tamanho com B", "64B", parseSize, formatSize, 64, "64"},
+		{"Taxa simples", "10M", parseRate, formatRate, 10e6, "10M"},
+		{"Taxa com K maiúsculo", "512K", parseRate, formatRate, 512e3, "512k"},
+		{"Taxa em bits", "1500", parseRate, formatRate, 1500, "1.5k"},
+		{"Taxa sem unidade exata", "1234", parseRate, formatRate, 1234, "1234"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(tt.value)
+			if err != nil || got != tt.expected {
+				t.Fatalf("expected: %d, got: %d %v", tt.expected, got, err)
+			}
+			if text := tt.format(got); text != tt.text {
+				t.Errorf("expected format: %s, got: %s", tt.text, text)
+			}
+		})
+	}
+	if _, err := ParseSize("1XB"); err == nil {
+		t.Errorf("expected an error for an invalid size")
+	}
+}
+
+func parseSize(value string) (uint64, error) {
+	s, err := ParseSize(value)
+	return uint64(s), err
+}
+
+func parseRate(value string) (uint64, error) {
+	r, err := ParseRate(value)
+	return uint64(r), err
+}
+
+func formatSize(n uint64) string {
+	return Size(n).String()
+}
+
+func formatRate(n uint64) string {
+	return Rate(n).String()
+}
+
+func TestParseRateLimit(t *testing.T) {
+	l, err := ParseRateLimit("10M/5M")
+	if err != nil || l.Upload != 10e6 || l.Download != 5e6 {
+		t.Fatalf("unexpected rate limit: %+v %v", l, err)
+	}
+	if l.String() != "10M/5M" {
+		t.Errorf("expected 10M/5M, got: %s", l)
+	}
+	if l, err := ParseRateLimit("10M"); err != nil || l.Upload != 10e6 || l.Download != 10e6 {
+		t.Errorf("expected a single rate for both directions, got: %+v %v", l, err)
+	}
+	if _, err := ParseRateLimit("10M/x"); err == nil {
+		t.Errorf("expected an error for an invalid rate")
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Time
+	}{
+		{"Formato v6", "jan/02/2024 10:00:00", time.Date(2024, time.January, 2, 10, 0, 0, 0, time.UTC)},
+		{"Formato v6 sem ano", "feb/28 23:59:59", time.Date(2024, time.February, 28, 23, 59, 59, 0, time.UTC)},
+		{"Formato v7", "2023-12-31 08:30:00", time.Date(2023, time.December, 31, 8, 30, 0, 0, time.UTC)},
+		{"Formato v7 sem ano", "03-01 01:02:03", time.Date(2024, time.March, 1, 1, 2, 3, 0, time.UTC)},
+		{"Somente hora", "10:20:30", time.Date(2024, time.March, 5, 10, 20, 30, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTime(tt.value, now, time.UTC)
+			if err != nil || !got.Equal(tt.expected) {
+				t.Fatalf("expected: %s, got: %s %v", tt.expected, got, err)
+			}
+		})
+	}
+	for _, value := range []string{"", "foo/02/2024 10:00:00", "2024-13-01 10:00:00", "10:00"} {
+		if _, err := ParseTime(value, now, time.UTC); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+
+	v := time.Date(2024, time.January, 2, 10, 0, 0, 0, time.UTC)
+	if got := FormatTime(v); got != "2024-01-02 10:00:00" {
+		t.Errorf("unexpected v7 format: %s", got)
+	}
+	if got := FormatTimeV6(v); got != "jan/02/2024 10:00:00" {
+		t.Errorf("unexpected v6 format: %s", got)
+	}
+	if got := FormatTimeV6(v.AddDate(0, 6, 0)); got != "jul/02/2024 10:00:00" {
+		t.Errorf("unexpected v6 format: %s", got)
+	}
+	for _, text := range []string{FormatTime(v), FormatTimeV6(v)} {
+		if back, err := ParseTime(text, now, time.UTC); err != nil || !back.Equal(v) {
+			t.Errorf("round trip of %s: got %s %v", text, back, err)
+		}
+	}
+}
+
+func TestValues(t *testing.T) {
+	t.Run("Booleano", func(t *testing.T) {
+		for value, expected := range map[string]bool{"yes": true, "true": true, "no": false, "false": false, "": false} {
+			if got, err := ParseBool(value); err != nil || got != expected {
+				t.Errorf("%q: expected: %v, got: %v %v", value, expected, got, err)
+			}
+		}
+		if _, err := ParseBool("maybe"); err == nil {
+			t.Errorf("expected an error")
+		}
+		if FormatBool(true) != "yes" || FormatBool(false) != "no" {
+			t.Errorf("unexpected boolean format")
+		}
+	})
+
+	t.Run("Valor negado", func(t *testing.T) {
+		n := ParseNegatable("!10.0.0.0/8")
+		if !n.Not || n.Value != "10.0.0.0/8" || n.String() != "!10.0.0.0/8" {
+			t.Errorf("unexpected negated value: %+v", n)
+		}
+		if n := ParseNegatable("ether1"); n.Not || n.String() != "ether1" {
+			t.Errorf("unexpected plain value: %+v", n)
+		}
+	})
+
+	t.Run("Portas", func(t *testing.T) {
+		p, err := ParsePortRanges("80,443,8000-8100")
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		expected := PortRanges{{80, 80}, {443, 443}, {8000, 8100}}
+		if !reflect.DeepEqual(p, expected) {
+			t.Fatalf("expected: %v, got: %v", expected, p)
+		}
+		if !p.Contains(8050) || p.Contains(81) {
+			t.Errorf("unexpected Contains result")
+		}
+		if p.String() != "80,443,8000-8100" {
+			t.Errorf("unexpected format: %s", p)
+		}
+		for _, value := range []string{"80-", "100-10", "70000"} {
+			if _, err := ParsePortRanges(value); err == nil {
+				t.Errorf("%q: expected an error", value)
+			}
+		}
+	})
+
+	t.Run("Intervalo de horário", func(t *testing.T) {
+		for _, value := range []string{"8h-17h,mon,fri", "08:00:00-17:00:00,mon,fri"} {
+			r, err := ParseTimeRange(value)
+			if err != nil {
+				t.Fatalf("%q: %v", value, err)
+			}
+			if r.Start != 8*time.Hour || r.End != 17*time.Hour || !reflect.DeepEqual(r.Days, []time.Weekday{time.Monday, time.Friday}) {
+				t.Fatalf("%q: unexpected range %+v", value, r)
+			}
+			if r.String() != "8h-17h,mon,fri" {
+				t.Errorf("unexpected format: %s", r)
+			}
+			monday := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+			if !r.Contains(monday) || r.Contains(monday.Add(24*time.Hour)) || r.Contains(monday.Add(9*time.Hour)) {
+				t.Errorf("unexpected Contains result")
+			}
+		}
+		night, _ := ParseTimeRange("22h-6h")
+		if !night.Contains(time.Date(2024, time.January, 1, 23, 0, 0, 0, time.UTC)) {
+			t.Errorf("expected a range past midnight to match")
+		}
+		if _, err := ParseTimeRange("8h-17h,xyz"); err == nil {
+			t.Errorf("expected an error for an invalid weekday")
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package rostypes
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type unit struct {
+	suffix string
+	factor uint64
+}
+
+var sizeUnits = []unit{{"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}}
+
+var rateUnits = []unit{{"G", 1e9}, {"M", 1e6}, {"k", 1e3}}
+
+// Size is an amount of bytes such as 1.2KiB or 512MiB
+type Size uint64
+
+// ParseSize parses a plain number of bytes or a value with the KiB, MiB,
+// GiB or TiB suffix, B alone is accepted as well
+func ParseSize(value string) (Size, error) {
+	n, err := parseUnits(value, append(sizeUnits[:len(sizeUnits):len(sizeUnits)], unit{"B", 1}))
+	if err != nil {
+		return 0, errors.New("invalid size " + strconv.Quote(value))
+	}
+	return Size(n), nil
+}
+
+// String formats the size with the largest unit that keeps it exact within
+// one decimal, e.g. 1.5KiB, or as a plain number of bytes
+func (s Size) String() string {
+	for _, u := range sizeUnits {
+		if text, ok := formatUnit(uint64(s), u.factor, u.suffix); ok {
+			return text
+		}
+	}
+	return strconv.FormatUint(uint64(s), 10)
+}
+
+// UnmarshalText parses the size
+func (s *Size) UnmarshalText(text []byte) error {
+	v, err := ParseSize(string(text))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// MarshalText formats the size
+func (s Size) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Rate is a rate in bits per second such as 10M or 512k
+type Rate uint64
+
+// ParseRate parses a plain number of bits per second or a value with the
+// k, M or G suffix (powers of 1000), K is accepted for k
+func ParseRate(value string) (Rate, error) {
+	n, err := parseUnits(value, append(rateUnits[:len(rateUnits):len(rateUnits)], unit{"K", 1e3}))
+	if err != nil {
+		return 0, errors.New("invalid rate " + strconv.Quote(value))
+	}
+	return Rate(n), nil
+}
+
+// String formats the rate as RouterOS does, e.g. 10M
+func (r Rate) String() string {
+	for _, u := range rateUnits {
+		if text, ok := formatUnit(uint64(r), u.factor, u.suffix); ok {
+			return text
+		}
+	}
+	return strconv.FormatUint(uint64(r), 10)
+}
+
+// UnmarshalText parses the rate
+func (r *Rate) UnmarshalText(text []byte) error {
+	v, err := ParseRate(string(text))
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// MarshalText formats the rate
+func (r Rate) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// RateLimit is a pair of rates such as the max-limit 10M/5M of a queue,
+// upload (target) first. A single rate applies to both directions.
+type RateLimit struct {
+	Upload   Rate
+	Download Rate
+}
+
+// ParseRateLimit parses upload/download rates
+func ParseRateLimit(value string) (RateLimit, error) {
+	up, down, ok := strings.Cut(value, "/")
+	if !ok {
+		down = up
+	}
+	u, err := ParseRate(up)
+	if err != nil {
+		return RateLimit{}, err
+	}
+	d, err := ParseRate(down)
+	if err != nil {
+		return RateLimit{}, err
+	}
+	return RateLimit{Upload: u, Download: d}, nil
+}
+
+func (l RateLimit) String() string {
+	return l.Upload.String() + "/" + l.Download.String()
+}
+
+// UnmarshalText parses the rate limit
+func (l *RateLimit) UnmarshalText(text []byte) error {
+	v, err := ParseRateLimit(string(text))
+	if err != nil {
+		return err
+	}
+	*l = v
+	return nil
+}
+
+// MarshalText formats the rate limit
+func (l RateLimit) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// parseUnits parses a number followed by one of the units
+func parseUnits(value string, units []unit) (uint64, error) {
+	if n, err := strconv.ParseUint(value, 10, 64); err == nil {
+		return n, nil
+	}
+	for _, u := range units {
+		number, ok := strings.CutSuffix(value, u.suffix)
+		if !ok {
+			continue
+		}
+		f, err := strconv.ParseFloat(number, 64)
+		if err != nil || f < 0 {
+			break
+		}
+		return uint64(math.Round(f * float64(u.factor))), nil
+	}
+	return 0, errors.New("invalid value")
+}
+
+// formatUnit formats n in the unit when it is exact within one decimal
+func formatUnit(n, factor uint64, suffix string) (string, bool) {
+	if n < factor {
+		return "", false
+	}
+	if n%factor == 0 {
+		return strconv.FormatUint(n/factor, 10) + suffix, true
+	}
+	tenths := n * 10 / factor
+	if tenths*factor == n*10 {
+		return strconv.FormatFloat(float64(tenths)/10, 'f', 1, 64) + suffix, true
+	}
+	return "", false
+}
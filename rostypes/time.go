@@ -0,0 +1,103 @@
+package rostypes
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LayoutV7 date and time since RouterOS 7.10, e.g. 2024-01-02 10:00:00. The
+// lower case months of older versions, e.g. jan/02/2024 10:00:00, have no Go
+// layout, see ParseTime and FormatTimeV6.
+const LayoutV7 = "2006-01-02 15:04:05"
+
+var months = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+
+// ParseTime parses a date and time in loc. Besides the full v6 and v7
+// formats, the short forms of the log are accepted relative to now: 10:00:00
+// (today), jan/02 10:00:00 (v6, this year) and 01-02 10:00:00 (v7, this year).
+func ParseTime(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	now = now.In(loc)
+	invalid := errors.New("invalid time " + strconv.Quote(value))
+
+	date, clock, ok := strings.Cut(strings.TrimSpace(value), " ")
+	if !ok {
+		date, clock = "", date
+	}
+	clockParts := strings.Split(clock, ":")
+	if len(clockParts) != 3 {
+		return time.Time{}, invalid
+	}
+	hour, err1 := strconv.Atoi(clockParts[0])
+	minute, err2 := strconv.Atoi(clockParts[1])
+	sec, err3 := strconv.ParseFloat(clockParts[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return time.Time{}, invalid
+	}
+
+	year, month, day := now.Date()
+	switch {
+	case date == "":
+	case strings.Contains(date, "/"):
+		// v6: jan/02/2006 or jan/02
+		parts := strings.Split(date, "/")
+		if len(parts) < 2 || len(parts) > 3 {
+			return time.Time{}, invalid
+		}
+		month = 0
+		for i, name := range months {
+			if strings.EqualFold(parts[0], name) {
+				month = time.Month(i + 1)
+			}
+		}
+		d, err := strconv.Atoi(parts[1])
+		if month == 0 || err != nil {
+			return time.Time{}, invalid
+		}
+		day = d
+		if len(parts) == 3 {
+			if year, err = strconv.Atoi(parts[2]); err != nil {
+				return time.Time{}, invalid
+			}
+		}
+	default:
+		// v7: 2006-01-02 or 01-02
+		parts := strings.Split(date, "-")
+		if len(parts) < 2 || len(parts) > 3 {
+			return time.Time{}, invalid
+		}
+		numbers := make([]int, len(parts))
+		for i, part := range parts {
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return time.Time{}, invalid
+			}
+			numbers[i] = n
+		}
+		if len(numbers) == 3 {
+			year, numbers = numbers[0], numbers[1:]
+		}
+		month, day = time.Month(numbers[0]), numbers[1]
+	}
+	if month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 || minute > 59 || sec >= 61 {
+		return time.Time{}, invalid
+	}
+
+	whole := int(sec)
+	nsec := int((sec - float64(whole)) * 1e9)
+	return time.Date(year, month, day, hour, minute, whole, nsec, loc), nil
+}
+
+// FormatTime formats t with the v7 layout
+func FormatTime(t time.Time) string {
+	return t.Format(LayoutV7)
+}
+
+// FormatTimeV6 formats t with the v6 layout, e.g. jan/02/2024 10:00:00
+func FormatTimeV6(t time.Time) string {
+	return months[t.Month()-1] + t.Format("/02/2006 15:04:05")
+}
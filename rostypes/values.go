@@ -0,0 +1,215 @@
+package rostypes
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseBool parses yes/no and true/false, an empty value is false
+func ParseBool(value string) (bool, error) {
+	switch value {
+	case "yes", "true":
+		return true, nil
+	case "no", "false", "":
+		return false, nil
+	}
+	return false, errors.New("invalid boolean " + strconv.Quote(value))
+}
+
+// FormatBool formats b as yes or no, accepted by v6 and v7
+func FormatBool(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// Negatable is a value that may be negated with !, such as the
+// src-address=!10.0.0.0/8 of a firewall rule
+type Negatable struct {
+	Not   bool
+	Value string
+}
+
+// ParseNegatable splits the leading ! from the value
+func ParseNegatable(value string) Negatable {
+	v, not := strings.CutPrefix(value, "!")
+	return Negatable{Not: not, Value: v}
+}
+
+func (n Negatable) String() string {
+	if n.Not {
+		return "!" + n.Value
+	}
+	return n.Value
+}
+
+// UnmarshalText parses the value
+func (n *Negatable) UnmarshalText(text []byte) error {
+	*n = ParseNegatable(string(text))
+	return nil
+}
+
+// MarshalText formats the value
+func (n Negatable) MarshalText() ([]byte, error) {
+	return []byte(n.String()), nil
+}
+
+// PortRange is an inclusive range of ports, From equals To for a single port
+type PortRange struct {
+	From uint16
+	To   uint16
+}
+
+func (r PortRange) String() string {
+	if r.From == r.To {
+		return strconv.Itoa(int(r.From))
+	}
+	return strconv.Itoa(int(r.From)) + "-" + strconv.Itoa(int(r.To))
+}
+
+// PortRanges is a port list such as 80,443,8000-8100
+type PortRanges []PortRange
+
+// ParsePortRanges parses a comma separated list of ports and ranges
+func ParsePortRanges(value string) (PortRanges, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var ranges PortRanges
+	for _, part := range strings.Split(value, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		if !isRange {
+			to = from
+		}
+		f, err1 := strconv.ParseUint(from, 10, 16)
+		t, err2 := strconv.ParseUint(to, 10, 16)
+		if err1 != nil || err2 != nil || f > t {
+			return nil, errors.New("invalid port range " + strconv.Quote(part))
+		}
+		ranges = append(ranges, PortRange{From: uint16(f), To: uint16(t)})
+	}
+	return ranges, nil
+}
+
+// Contains reports whether the port is in one of the ranges
+func (p PortRanges) Contains(port uint16) bool {
+	for _, r := range p {
+		if port >= r.From && port <= r.To {
+			return true
+		}
+	}
+	return false
+}
+
+func (p PortRanges) String() string {
+	parts := make([]string, len(p))
+	for i, r := range p {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// UnmarshalText parses the port list
+func (p *PortRanges) UnmarshalText(text []byte) error {
+	v, err := ParsePortRanges(string(text))
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// MarshalText formats the port list
+func (p PortRanges) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// TimeRange is the time matcher of firewall rules and queues, such as
+// 8h-17h,mon,tue,wed,thu,fri. Start and End are offsets from midnight and
+// Days is empty when every day matches.
+type TimeRange struct {
+	Start time.Duration
+	End   time.Duration
+	Days  []time.Weekday
+}
+
+// ParseTimeRange parses start-end followed by optional weekdays, the times
+// may use the v6 (08:00:00) or the v7 (8h) format
+func ParseTimeRange(value string) (TimeRange, error) {
+	invalid := errors.New("invalid time range " + strconv.Quote(value))
+	parts := strings.Split(value, ",")
+	start, end, ok := strings.Cut(parts[0], "-")
+	if !ok {
+		return TimeRange{}, invalid
+	}
+	var r TimeRange
+	var err error
+	if r.Start, err = ParseDuration(start); err != nil {
+		return TimeRange{}, invalid
+	}
+	if r.End, err = ParseDuration(end); err != nil {
+		return TimeRange{}, invalid
+	}
+	for _, day := range parts[1:] {
+		found := false
+		for i, name := range weekdays {
+			if strings.EqualFold(strings.TrimSpace(day), name) {
+				r.Days = append(r.Days, time.Weekday(i))
+				found = true
+			}
+		}
+		if !found {
+			return TimeRange{}, invalid
+		}
+	}
+	return r, nil
+}
+
+// Contains reports whether t falls in the range
+func (r TimeRange) Contains(t time.Time) bool {
+	if len(r.Days) > 0 {
+		found := false
+		for _, d := range r.Days {
+			if d == t.Weekday() {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if r.Start <= r.End {
+		return offset >= r.Start && offset < r.End
+	}
+	// the range wraps past midnight
+	return offset >= r.Start || offset < r.End
+}
+
+func (r TimeRange) String() string {
+	parts := []string{FormatDuration(r.Start) + "-" + FormatDuration(r.End)}
+	for _, d := range r.Days {
+		parts = append(parts, weekdays[d])
+	}
+	return strings.Join(parts, ",")
+}
+
+// UnmarshalText parses the time range
+func (r *TimeRange) UnmarshalText(text []byte) error {
+	v, err := ParseTimeRange(string(text))
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// MarshalText formats the time range
+func (r TimeRange) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
@@ -0,0 +1,19 @@
+package routerossim
+
+import "github.com/leandrose/go-routeros/internal/rosquery"
+
+// get returns an attribute of the item, including .id
+func (it *item) get(name string) (string, bool) {
+	if name == ".id" {
+		return it.id, true
+	}
+	v, ok := it.values[name]
+	return v, ok
+}
+
+// match evaluates the query words of a print or listen against the item. The
+// words push booleans on a stack, ?# applies operations to the stack and the
+// item matches when every value left on the stack is true.
+func match(queries []string, it *item) (bool, error) {
+	return rosquery.Match(queries, it.get)
+}
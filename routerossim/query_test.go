@@ -0,0 +1,46 @@
+package routerossim
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	it := &item{id: "*1", values: map[string]string{"name": "ether1", "type": "ether", "mtu": "1500", "disabled": "false"}}
+
+	tests := []struct {
+		name     string
+		queries  []string
+		expected bool
+	}{
+		{"Empty", nil, true},
+		{"Equal", []string{"?name=ether1"}, true},
+		{"Equal with leading =", []string{"?=name=ether1"}, true},
+		{"Has", []string{"?mtu"}, true},
+		{"Id", []string{"?.id=*1"}, true},
+		{"Other id", []string{"?.id=*2"}, false},
+		{"Missing", []string{"?-comment"}, true},
+		{"Less numeric", []string{"?<mtu=9000"}, true},
+		{"Greater numeric", []string{"?>mtu=9000"}, false},
+		{"Implicit and", []string{"?type=ether", "?disabled=true"}, false},
+		{"Or", []string{"?type=wlan", "?type=ether", "?#|"}, true},
+		{"Not", []string{"?type=ether", "?#!"}, false},
+		{"Index copy", []string{"?type=wlan", "?type=ether", "?#1!|0"}, true},
+		{"Index replaces stack", []string{"?type=ether", "?type=wlan", "?#1"}, true},
+		{"Dot copies top", []string{"?type=ether", "?#.!|"}, true},
+		{"Dot after index", []string{"?type=wlan", "?type=ether", "?#1.&"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := match(tt.queries, it)
+			if err != nil {
+				t.Fatalf("match: %v", err)
+			}
+			if ok != tt.expected {
+				t.Errorf("expected: %v, got: %v", tt.expected, ok)
+			}
+		})
+	}
+
+	if _, err := match([]string{"?#|"}, it); err == nil {
+		t.Errorf("expected an error for an operation on an empty stack")
+	}
+}
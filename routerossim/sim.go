@@ -0,0 +1,335 @@
+// Package routerossim is a stateful RouterOS simulator. It keeps an in-memory
+// model of menus and answers print, add, set, remove, enable, disable, unset,
+// getall and listen over the API protocol through a routerostest server.
+package routerossim
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/leandrose/go-routeros/routerostest"
+)
+
+// DefaultMenus menus created by New with their unique attributes
+var DefaultMenus = map[string][]string{
+	"/ip/address": nil,
+	"/interface":  {"name"},
+	"/ppp/secret": {"name"},
+}
+
+type item struct {
+	id     string
+	values map[string]string
+}
+
+// words returns the item as attribute words, .id first and the rest sorted
+func (it *item) words(proplist []string) []string {
+	var words []string
+	if proplist == nil {
+		words = append(words, "=.id="+it.id)
+		keys := make([]string, 0, len(it.values))
+		for k := range it.values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			words = append(words, "="+k+"="+it.values[k])
+		}
+		return words
+	}
+	for _, k := range proplist {
+		if k == ".id" {
+			words = append(words, "=.id="+it.id)
+		} else if v, ok := it.values[k]; ok {
+			words = append(words, "="+k+"="+v)
+		}
+	}
+	return words
+}
+
+type listener struct {
+	w       *routerostest.ResponseWriter
+	queries []string
+}
+
+type menu struct {
+	items     []*item
+	nextID    int
+	unique    []string
+	listeners map[*listener]struct{}
+}
+
+func (m *menu) find(id string) *item {
+	for _, it := range m.items {
+		if it.id == id {
+			return it
+		}
+	}
+	return nil
+}
+
+// notify sends the item to the listeners whose query matches, dead items are
+// sent as .id with .dead=yes
+func (m *menu) notify(it *item, dead bool) {
+	for l := range m.listeners {
+		if dead {
+			_ = l.w.Re("=.id="+it.id, "=.dead=yes")
+			continue
+		}
+		if ok, _ := match(l.queries, it); ok {
+			_ = l.w.Re(it.words(nil)...)
+		}
+	}
+}
+
+// Simulator is an in-memory RouterOS
+type Simulator struct {
+	mu    sync.Mutex
+	menus map[string]*menu
+}
+
+// New returns a simulator with the DefaultMenus
+func New() *Simulator {
+	s := &Simulator{menus: make(map[string]*menu)}
+	for path, unique := range DefaultMenus {
+		s.AddMenu(path, unique...)
+	}
+	return s
+}
+
+// AddMenu creates a menu, add and set fail when an item already has the same
+// value of one of the unique attributes
+func (s *Simulator) AddMenu(path string, unique ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.menus[path] = &menu{
+		nextID:    1,
+		unique:    unique,
+		listeners: make(map[*listener]struct{}),
+	}
+}
+
+// Add adds an item to the menu and returns its .id
+func (s *Simulator) Add(path string, values map[string]string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.menus[path]
+	if !ok {
+		return "", fmt.Errorf("no such menu %s", path)
+	}
+	return s.add(m, values)
+}
+
+// Items returns a copy of the items of the menu, .id included
+func (s *Simulator) Items(path string) []map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.menus[path]
+	if !ok {
+		return nil
+	}
+	items := make([]map[string]string, 0, len(m.items))
+	for _, it := range m.items {
+		values := map[string]string{".id": it.id}
+		for k, v := range it.values {
+			values[k] = v
+		}
+		items = append(items, values)
+	}
+	return items
+}
+
+// Server returns a fake API server backed by the simulator
+func (s *Simulator) Server() *routerostest.Server {
+	server := routerostest.NewServer()
+	server.Handle("/", s)
+	return server
+}
+
+// ServeRouterOS implements routerostest.Handler
+func (s *Simulator) ServeRouterOS(ctx context.Context, w *routerostest.ResponseWriter, cmd *routerostest.Command) {
+	i := strings.LastIndex(cmd.Path, "/")
+	path, command := cmd.Path[:i], cmd.Path[i+1:]
+	attrs := attributes(cmd.Words)
+
+	s.mu.Lock()
+	m, ok := s.menus[path]
+	if !ok {
+		s.mu.Unlock()
+		_ = w.Trap(0, "no such command prefix")
+		return
+	}
+
+	var err error
+	switch command {
+	case "print", "getall":
+		err = s.print(w, m, cmd, attrs)
+	case "add":
+		delete(attrs, ".id")
+		var id string
+		if id, err = s.add(m, attrs); err == nil {
+			s.mu.Unlock()
+			_ = w.Done("=ret=" + id)
+			return
+		}
+	case "set", "unset", "remove", "enable", "disable":
+		err = s.modify(m, command, attrs)
+	case "listen":
+		l := &listener{w: w, queries: cmd.Queries()}
+		m.listeners[l] = struct{}{}
+		s.mu.Unlock()
+		w.Detach()
+		<-ctx.Done()
+		s.mu.Lock()
+		delete(m.listeners, l)
+		s.mu.Unlock()
+		return
+	default:
+		err = &trap{category: 0, message: "no such command"}
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		if t, ok := err.(*trap); ok {
+			_ = w.Trap(t.category, t.message)
+		} else {
+			_ = w.Trap(-1, err.Error())
+		}
+	}
+}
+
+type trap struct {
+	category int
+	message  string
+}
+
+func (t *trap) Error() string {
+	return t.message
+}
+
+// attributes returns the =name=value words of a command
+func attributes(words []string) map[string]string {
+	attrs := make(map[string]string)
+	for _, word := range words {
+		if !strings.HasPrefix(word, "=") {
+			continue
+		}
+		k, v, _ := strings.Cut(word[1:], "=")
+		attrs[k] = v
+	}
+	return attrs
+}
+
+func (s *Simulator) print(w *routerostest.ResponseWriter, m *menu, cmd *routerostest.Command, attrs map[string]string) error {
+	var proplist []string
+	if v, ok := attrs[".proplist"]; ok {
+		proplist = strings.Split(v, ",")
+	}
+	_, countOnly := attrs["count-only"]
+
+	count := 0
+	for _, it := range m.items {
+		ok, err := match(cmd.Queries(), it)
+		if err != nil {
+			return &trap{category: 1, message: err.Error()}
+		}
+		if !ok {
+			continue
+		}
+		count++
+		if !countOnly {
+			_ = w.Re(it.words(proplist)...)
+		}
+	}
+	if countOnly {
+		_ = w.Done("=ret=" + strconv.Itoa(count))
+	}
+	return nil
+}
+
+func (s *Simulator) add(m *menu, values map[string]string) (string, error) {
+	if err := m.checkUnique(nil, values); err != nil {
+		return "", err
+	}
+	it := &item{
+		id:     fmt.Sprintf("*%X", m.nextID),
+		values: map[string]string{"disabled": "false"},
+	}
+	m.nextID++
+	for k, v := range values {
+		it.values[k] = v
+	}
+	m.items = append(m.items, it)
+	m.notify(it, false)
+	return it.id, nil
+}
+
+func (s *Simulator) modify(m *menu, command string, attrs map[string]string) error {
+	ids, ok := attrs[".id"]
+	if !ok {
+		ids, ok = attrs["numbers"]
+	}
+	if !ok {
+		return &trap{category: 1, message: "no such item"}
+	}
+	delete(attrs, ".id")
+	delete(attrs, "numbers")
+
+	var items []*item
+	for _, id := range strings.Split(ids, ",") {
+		it := m.find(id)
+		if it == nil {
+			return &trap{category: -1, message: "no such item"}
+		}
+		items = append(items, it)
+	}
+
+	for _, it := range items {
+		switch command {
+		case "set":
+			if err := m.checkUnique(it, attrs); err != nil {
+				return err
+			}
+			for k, v := range attrs {
+				it.values[k] = v
+			}
+		case "unset":
+			delete(it.values, attrs["value-name"])
+		case "enable":
+			it.values["disabled"] = "false"
+		case "disable":
+			it.values["disabled"] = "true"
+		case "remove":
+			for i, current := range m.items {
+				if current == it {
+					m.items = append(m.items[:i], m.items[i+1:]...)
+					break
+				}
+			}
+			m.notify(it, true)
+			continue
+		}
+		m.notify(it, false)
+	}
+	return nil
+}
+
+// checkUnique fails when another item than self has the same unique value
+func (m *menu) checkUnique(self *item, values map[string]string) error {
+	for _, name := range m.unique {
+		v, ok := values[name]
+		if !ok {
+			continue
+		}
+		for _, it := range m.items {
+			if it != self && it.values[name] == v {
+				return &trap{category: -1, message: fmt.Sprintf("failure: already have such %s", name)}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package routerossim_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_routeros "github.com/leandrose/go-routeros"
+	"github.com/leandrose/go-routeros/routerossim"
+)
+
+func TestSimulatorFlow(t *testing.T) {
+	sim := routerossim.New()
+	if _, err := sim.Add("/interface", map[string]string{"name": "ether1", "type": "ether"}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	client := go_routeros.NewClient(sim.Server().Pipe())
+	if err := client.Login("admin", ""); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	listen, err := client.SendCommandContext(ctx, "/ppp/secret/listen")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	reply, err := client.Run(ctx, "/ppp/secret/add", "=name=alice", "=password=a", "=profile=default")
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	alice := reply.Ret()
+	if alice != "*1" {
+		t.Errorf("expected ret *1, got: %s", alice)
+	}
+	if _, err := client.Run(ctx, "/ppp/secret/add", "=name=bob", "=password=b", "=profile=vip"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := client.Run(ctx, "/ppp/secret/add", "=name=alice"); !errors.Is(err, go_routeros.ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists, got: %v", err)
+	}
+
+	reply, err = client.Run(ctx, "/ppp/secret/print", "=.proplist=.id,name", "?profile=vip", "?name=alice", "?#|")
+	if err != nil {
+		t.Fatalf("print: %v", err)
+	}
+	if len(reply.Re) != 2 {
+		t.Fatalf("expected 2 secrets, got: %v", reply.Re)
+	}
+	if _, ok := reply.Re[0].Get("password"); ok {
+		t.Errorf("expected only the .proplist attributes, got: %v", reply.Re[0])
+	}
+
+	if _, err := client.Run(ctx, "/ppp/secret/disable", "=.id="+alice); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	reply, err = client.Run(ctx, "/ppp/secret/print", "=count-only=", "?disabled=true")
+	if err != nil || reply.Ret() != "1" {
+		t.Fatalf("expected 1 disabled secret, got: %v %v", reply, err)
+	}
+	if _, err := client.Run(ctx, "/ppp/secret/remove", "=.id="+alice); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, err := client.Run(ctx, "/ppp/secret/set", "=.id="+alice, "=profile=vip"); !errors.Is(err, go_routeros.ErrNoSuchItem) {
+		t.Errorf("expected ErrNoSuchItem, got: %v", err)
+	}
+
+	// add alice, add bob, disable alice, remove alice
+	expected := []struct{ name, dead string }{{"alice", ""}, {"bob", ""}, {"alice", ""}, {"", "yes"}}
+	for _, e := range expected {
+		select {
+		case response := <-listen.Responses():
+			if response.Sentence.Value("name") != e.name || response.Sentence.Value(".dead") != e.dead {
+				t.Errorf("unexpected listen event: %v", response.Sentence)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for listen events")
+		}
+	}
+
+	if items := sim.Items("/ppp/secret"); len(items) != 1 || items[0]["name"] != "bob" {
+		t.Errorf("unexpected state: %v", items)
+	}
+}
@@ -0,0 +1,471 @@
+// Package routerostest provides a fake RouterOS API server that speaks the
+// length-prefixed word protocol, to test code built on go-routeros without a
+// router.
+package routerostest
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LoginStyle the login exchange expected by the server
+type LoginStyle int
+
+const (
+	// LoginPlain name and password sent in /login, RouterOS 6.43 and newer
+	LoginPlain LoginStyle = iota
+	// LoginChallenge /login answers with =ret= and expects an MD5 =response=
+	LoginChallenge
+)
+
+// Command is a command received by the server
+type Command struct {
+	// Path the command word, e.g. /ip/address/print
+	Path string
+	// Words the words after the command word, without the .tag
+	Words []string
+	// Tag the .tag of the command
+	Tag string
+}
+
+// Attr returns the value of the =name=value word
+func (c *Command) Attr(name string) (string, bool) {
+	prefix := "=" + name + "="
+	for _, word := range c.Words {
+		if strings.HasPrefix(word, prefix) {
+			return word[len(prefix):], true
+		}
+	}
+	return "", false
+}
+
+// Queries returns the query words (?...) in order
+func (c *Command) Queries() []string {
+	var queries []string
+	for _, word := range c.Words {
+		if strings.HasPrefix(word, "?") {
+			queries = append(queries, word)
+		}
+	}
+	return queries
+}
+
+// Handler answers the commands received by the server. ctx is cancelled when
+// the client sends /cancel for the command or the connection closes. The next
+// command of the connection starts once the handler returns, writes a reply
+// or calls Detach, a /cancel is answered at any time.
+type Handler interface {
+	ServeRouterOS(ctx context.Context, w *ResponseWriter, cmd *Command)
+}
+
+// HandlerFunc adapts a function to Handler
+type HandlerFunc func(ctx context.Context, w *ResponseWriter, cmd *Command)
+
+// ServeRouterOS calls f
+func (f HandlerFunc) ServeRouterOS(ctx context.Context, w *ResponseWriter, cmd *Command) {
+	f(ctx, w, cmd)
+}
+
+// Server is a fake RouterOS API server
+type Server struct {
+	// Username and Password accepted by /login
+	Username string
+	Password string
+	// LoginStyle the login exchange expected
+	LoginStyle LoginStyle
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	received []Command
+	conns    map[*serverConn]struct{}
+	listener net.Listener
+}
+
+// NewServer returns a server accepting admin with an empty password
+func NewServer() *Server {
+	return &Server{
+		Username: "admin",
+		handlers: make(map[string]Handler),
+		conns:    make(map[*serverConn]struct{}),
+	}
+}
+
+// Handle registers the handler for a command path. A path ending with "/"
+// handles every command below it unless a longer path matches.
+func (s *Server) Handle(path string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[path] = handler
+}
+
+// HandleFunc registers a function as handler for a command path
+func (s *Server) HandleFunc(path string, f func(ctx context.Context, w *ResponseWriter, cmd *Command)) {
+	s.Handle(path, HandlerFunc(f))
+}
+
+// Reply registers a scripted handler for a command path
+func (s *Server) Reply(path string, steps ...Step) {
+	s.Handle(path, Script(steps...))
+}
+
+// Received returns the commands received so far, /login excluded
+func (s *Server) Received() []Command {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Command(nil), s.received...)
+}
+
+// Pipe returns the client end of an in-memory connection served by s
+func (s *Server) Pipe() net.Conn {
+	client, server := net.Pipe()
+	go s.Serve(server)
+	return client
+}
+
+// Listen serves connections on a loopback listener and returns its address
+func (s *Server) Listen() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	return s.serveListener(l), nil
+}
+
+// ListenTLS is like Listen for API-SSL, config carries the server certificate
+func (s *Server) ListenTLS(config *tls.Config) (string, error) {
+	l, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	if err != nil {
+		return "", err
+	}
+	return s.serveListener(l), nil
+}
+
+func (s *Server) serveListener(l net.Listener) string {
+	s.mu.Lock()
+	s.listener = l
+	s.mu.Unlock()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.Serve(conn)
+		}
+	}()
+	return l.Addr().String()
+}
+
+// CloseConnections drops every open connection, as a router reboot would
+func (s *Server) CloseConnections() {
+	s.mu.Lock()
+	conns := make([]*serverConn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+	for _, c := range conns {
+		c.close()
+	}
+}
+
+// Close stops the listener and drops every open connection
+func (s *Server) Close() {
+	s.mu.Lock()
+	if s.listener != nil {
+		_ = s.listener.Close()
+	}
+	s.mu.Unlock()
+	s.CloseConnections()
+}
+
+// Serve speaks the API protocol on conn until it is closed
+func (s *Server) Serve(conn net.Conn) {
+	c := &serverConn{
+		server:  s,
+		conn:    conn,
+		running: make(map[string]*ResponseWriter),
+		notify:  make(chan struct{}, 1),
+		closed:  make(chan struct{}),
+	}
+	s.mu.Lock()
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		c.close()
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+	}()
+
+	go c.dispatchLoop()
+	reader := bufio.NewReader(conn)
+	for {
+		words, err := readSentence(reader)
+		if err != nil {
+			return
+		}
+		if len(words) == 0 {
+			continue
+		}
+		cmd := &Command{Path: words[0]}
+		for _, word := range words[1:] {
+			if strings.HasPrefix(word, ".tag=") {
+				cmd.Tag = strings.TrimPrefix(word, ".tag=")
+				continue
+			}
+			cmd.Words = append(cmd.Words, word)
+		}
+		if cmd.Path == "/cancel" {
+			// a /cancel does not wait for the running commands to start
+			c.cancel(cmd)
+			continue
+		}
+		c.enqueue(cmd)
+	}
+}
+
+// handler returns the handler registered for path
+func (s *Server) handler(path string) Handler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if h, ok := s.handlers[path]; ok {
+		return h
+	}
+	patterns := make([]string, 0, len(s.handlers))
+	for pattern := range s.handlers {
+		if strings.HasSuffix(pattern, "/") && strings.HasPrefix(path, pattern) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		return len(patterns[i]) > len(patterns[j])
+	})
+	return s.handlers[patterns[0]]
+}
+
+type serverConn struct {
+	server    *Server
+	conn      net.Conn
+	writeLock sync.Mutex
+	mu        sync.Mutex
+	loggedIn  bool
+	challenge []byte
+	running   map[string]*ResponseWriter
+	// queue the commands read and not dispatched yet
+	queue     []queued
+	notify    chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+type queued struct {
+	cmd *Command
+	w   *ResponseWriter
+	ctx context.Context
+}
+
+func (c *serverConn) close() {
+	c.closeOnce.Do(func() {
+		_ = c.conn.Close()
+		close(c.closed)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, w := range c.running {
+			w.cancel()
+		}
+	})
+}
+
+func (c *serverConn) write(words ...string) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	_, err := c.conn.Write(encodeSentence(words))
+	return err
+}
+
+// enqueue queues a command for dispatchLoop, it can be cancelled from now on
+func (c *serverConn) enqueue(cmd *Command) {
+	q := queued{cmd: cmd, w: &ResponseWriter{conn: c, tag: cmd.Tag, started: make(chan struct{})}}
+	q.ctx, q.w.cancel = context.WithCancel(context.Background())
+	c.mu.Lock()
+	if cmd.Tag != "" && cmd.Path != "/login" {
+		c.running[cmd.Tag] = q.w
+	}
+	c.queue = append(c.queue, q)
+	loggedIn := c.loggedIn
+	c.mu.Unlock()
+	if loggedIn && cmd.Path != "/login" {
+		c.record(cmd)
+	}
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchLoop starts the queued commands in order
+func (c *serverConn) dispatchLoop() {
+	for {
+		c.mu.Lock()
+		for len(c.queue) == 0 {
+			c.mu.Unlock()
+			select {
+			case <-c.notify:
+			case <-c.closed:
+				return
+			}
+			c.mu.Lock()
+		}
+		q := c.queue[0]
+		c.queue[0] = queued{}
+		c.queue = c.queue[1:]
+		c.mu.Unlock()
+		c.dispatch(q)
+	}
+}
+
+// cancel answers /cancel, the commands with the =tag= or every command
+// without it are interrupted
+func (c *serverConn) cancel(cmd *Command) {
+	w := &ResponseWriter{conn: c, tag: cmd.Tag, started: make(chan struct{})}
+	c.mu.Lock()
+	loggedIn := c.loggedIn
+	c.mu.Unlock()
+	if !loggedIn {
+		_ = w.Trap(-1, "not logged in")
+		_ = w.Done()
+		return
+	}
+
+	c.record(cmd)
+	tag, ok := cmd.Attr("tag")
+	var interrupt []*ResponseWriter
+	c.mu.Lock()
+	for t, running := range c.running {
+		if !ok || t == tag {
+			interrupt = append(interrupt, running)
+		}
+	}
+	c.mu.Unlock()
+	for _, running := range interrupt {
+		running.interrupt()
+	}
+	_ = w.Done()
+}
+
+// record adds the command to Received, in the order of the wire
+func (c *serverConn) record(cmd *Command) {
+	c.server.mu.Lock()
+	defer c.server.mu.Unlock()
+	c.server.received = append(c.server.received, *cmd)
+}
+
+// done removes a finished command from the running ones
+func (c *serverConn) done(w *ResponseWriter) {
+	w.cancel()
+	c.mu.Lock()
+	if c.running[w.tag] == w {
+		delete(c.running, w.tag)
+	}
+	c.mu.Unlock()
+}
+
+func (c *serverConn) dispatch(q queued) {
+	cmd, w := q.cmd, q.w
+
+	c.mu.Lock()
+	loggedIn := c.loggedIn
+	c.mu.Unlock()
+
+	if cmd.Path == "/login" {
+		c.login(w, cmd)
+		return
+	}
+	if !loggedIn {
+		c.done(w)
+		_ = w.Trap(-1, "not logged in")
+		_ = w.Done()
+		return
+	}
+
+	handler := c.server.handler(cmd.Path)
+	if handler == nil {
+		c.done(w)
+		_ = w.Trap(0, "no such command")
+		_ = w.Done()
+		return
+	}
+
+	go func() {
+		defer func() {
+			w.Detach()
+			c.done(w)
+			_ = w.Done()
+		}()
+		handler.ServeRouterOS(q.ctx, w, cmd)
+	}()
+	<-w.started
+}
+
+func (c *serverConn) login(w *ResponseWriter, cmd *Command) {
+	name, _ := cmd.Attr("name")
+	s := c.server
+
+	switch s.LoginStyle {
+	case LoginChallenge:
+		response, ok := cmd.Attr("response")
+		c.mu.Lock()
+		challenge := c.challenge
+		c.mu.Unlock()
+		if !ok || challenge == nil {
+			challenge = make([]byte, 16)
+			_, _ = rand.Read(challenge)
+			c.mu.Lock()
+			c.challenge = challenge
+			c.mu.Unlock()
+			_ = w.Done("=ret=" + hex.EncodeToString(challenge))
+			return
+		}
+		if name != s.Username || response != challengeResponse(challenge, s.Password) {
+			break
+		}
+		c.mu.Lock()
+		c.loggedIn = true
+		c.mu.Unlock()
+		_ = w.Done()
+		return
+	default:
+		password, _ := cmd.Attr("password")
+		if name != s.Username || password != s.Password {
+			break
+		}
+		c.mu.Lock()
+		c.loggedIn = true
+		c.mu.Unlock()
+		_ = w.Done()
+		return
+	}
+	_ = w.Trap(-1, "invalid user name or password (6)")
+	_ = w.Done()
+}
+
+func challengeResponse(challenge []byte, password string) string {
+	h := md5.New() //nolint:gosec
+	h.Write([]byte{0})
+	h.Write([]byte(password))
+	h.Write(challenge)
+	return fmt.Sprintf("00%x", h.Sum(nil))
+}
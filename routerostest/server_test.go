@@ -0,0 +1,145 @@
+package routerostest
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wireClient speaks the API protocol with raw sentences
+type wireClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func newWireClient(t *testing.T, s *Server) *wireClient {
+	conn := s.Pipe()
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+	return &wireClient{t: t, conn: conn, reader: bufio.NewReader(conn)}
+}
+
+func (c *wireClient) send(words ...string) {
+	c.t.Helper()
+	if _, err := c.conn.Write(encodeSentence(words)); err != nil {
+		c.t.Fatalf("send %v: %v", words, err)
+	}
+}
+
+func (c *wireClient) read() []string {
+	c.t.Helper()
+	words, err := readSentence(c.reader)
+	if err != nil {
+		c.t.Fatalf("read: %v", err)
+	}
+	return words
+}
+
+func (c *wireClient) expect(expected ...string) {
+	c.t.Helper()
+	if got := c.read(); !reflect.DeepEqual(got, expected) {
+		c.t.Fatalf("expected: %q, got: %q", expected, got)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	t.Run("Login simples", func(t *testing.T) {
+		s := NewServer()
+		s.Password = "secret"
+		c := newWireClient(t, s)
+		c.send("/login", "=name=admin", "=password=wrong", ".tag=1")
+		c.expect("!trap", "=message=invalid user name or password (6)", ".tag=1")
+		c.expect("!done", ".tag=1")
+		c.send("/login", "=name=admin", "=password=secret", ".tag=2")
+		c.expect("!done", ".tag=2")
+	})
+
+	t.Run("Login com desafio", func(t *testing.T) {
+		s := NewServer()
+		s.Password = "secret"
+		s.LoginStyle = LoginChallenge
+		c := newWireClient(t, s)
+		c.send("/login")
+		done := c.read()
+		if len(done) != 2 || done[0] != "!done" || !strings.HasPrefix(done[1], "=ret=") {
+			t.Fatalf("expected the challenge, got: %q", done)
+		}
+		challenge, err := hex.DecodeString(strings.TrimPrefix(done[1], "=ret="))
+		if err != nil {
+			t.Fatalf("challenge: %v", err)
+		}
+		c.send("/login", "=name=admin", "=response="+challengeResponse(challenge, "secret"))
+		c.expect("!done")
+	})
+
+	t.Run("Comando sem login", func(t *testing.T) {
+		s := NewServer()
+		c := newWireClient(t, s)
+		c.send("/system/identity/print", ".tag=1")
+		c.expect("!trap", "=message=not logged in", ".tag=1")
+		c.expect("!done", ".tag=1")
+		if received := s.Received(); len(received) != 0 {
+			t.Errorf("expected no command received, got: %+v", received)
+		}
+	})
+}
+
+func TestCancel(t *testing.T) {
+	s := NewServer()
+	// waits for the cancellation without Detach
+	s.HandleFunc("/interface/listen", func(ctx context.Context, w *ResponseWriter, cmd *Command) {
+		<-ctx.Done()
+	})
+	s.Reply("/system/identity/print", Re("=name=MikroTik"))
+	c := newWireClient(t, s)
+	c.send("/login", "=name=admin", "=password=")
+	c.expect("!done")
+
+	c.send("/interface/listen", ".tag=1")
+	c.send("/system/identity/print", ".tag=2")
+	c.send("/cancel", "=tag=1", ".tag=3")
+	// the command queued behind the cancelled one runs afterwards, the
+	// replies of different tags may interleave
+	byTag := make(map[string][]string)
+	for i := 0; i < 5; i++ {
+		words := c.read()
+		tag := words[len(words)-1]
+		byTag[tag] = append(byTag[tag], words[0])
+	}
+	expected := map[string][]string{
+		".tag=1": {"!trap", "!done"},
+		".tag=2": {"!re", "!done"},
+		".tag=3": {"!done"},
+	}
+	if !reflect.DeepEqual(byTag, expected) {
+		t.Errorf("expected: %v, got: %v", expected, byTag)
+	}
+
+	var paths []string
+	for _, cmd := range s.Received() {
+		paths = append(paths, cmd.Path)
+	}
+	if expected := []string{"/interface/listen", "/system/identity/print", "/cancel"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected: %v, got: %v", expected, paths)
+	}
+}
+
+func TestFatal(t *testing.T) {
+	s := NewServer()
+	s.Reply("/quit", Fatal("session terminated on request"))
+	c := newWireClient(t, s)
+	c.send("/login", "=name=admin", "=password=")
+	c.expect("!done")
+
+	c.send("/quit", ".tag=1")
+	c.expect("!fatal", "=message=session terminated on request")
+	if _, err := readSentence(c.reader); err == nil {
+		t.Errorf("expected the connection to be closed")
+	}
+}
@@ -0,0 +1,89 @@
+package routerostest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// readSentence reads the words of a sentence up to the empty word
+func readSentence(r *bufio.Reader) ([]string, error) {
+	var words []string
+	for {
+		length, err := readLength(r)
+		if err != nil {
+			return nil, err
+		}
+		if length == 0 {
+			return words, nil
+		}
+		word := make([]byte, length)
+		if _, err := io.ReadFull(r, word); err != nil {
+			return nil, err
+		}
+		words = append(words, string(word))
+	}
+}
+
+func readLength(r *bufio.Reader) (int, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	var extra int
+	switch {
+	case first&0x80 == 0x00:
+		return int(first), nil
+	case first&0xC0 == 0x80:
+		extra, first = 1, first&^0xC0
+	case first&0xE0 == 0xC0:
+		extra, first = 2, first&^0xE0
+	case first&0xF0 == 0xE0:
+		extra, first = 3, first&^0xF0
+	case first == 0xF0:
+		b := make([]byte, 4)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return 0, err
+		}
+		return int(binary.BigEndian.Uint32(b)), nil
+	default:
+		return 0, errors.New("invalid length header")
+	}
+	length := int(first)
+	for i := 0; i < extra; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		length = length<<8 | int(b)
+	}
+	return length, nil
+}
+
+// encodeSentence encodes the words followed by the empty word
+func encodeSentence(words []string) []byte {
+	buf := &bytes.Buffer{}
+	for _, word := range words {
+		writeLength(buf, len(word))
+		buf.WriteString(word)
+	}
+	buf.WriteByte(0)
+	return buf.Bytes()
+}
+
+func writeLength(buf *bytes.Buffer, length int) {
+	switch {
+	case length < 0x80:
+		buf.WriteByte(byte(length))
+	case length < 0x4000:
+		buf.Write([]byte{byte(length>>8) | 0x80, byte(length)})
+	case length < 0x200000:
+		buf.Write([]byte{byte(length>>16) | 0xC0, byte(length >> 8), byte(length)})
+	case length < 0x10000000:
+		buf.Write([]byte{byte(length>>24) | 0xE0, byte(length >> 16), byte(length >> 8), byte(length)})
+	default:
+		buf.Write([]byte{0xF0, byte(length >> 24), byte(length >> 16), byte(length >> 8), byte(length)})
+	}
+}
@@ -0,0 +1,182 @@
+package routerostest
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrFinished is returned when writing to a command that already finished or
+// was cancelled
+var ErrFinished = errors.New("command finished")
+
+// ResponseWriter writes the replies of a command, the .tag is added to every
+// sentence. A !done is sent when the handler returns without sending one.
+//
+// Like RouterOS, the commands of a connection start in order: the next
+// command is dispatched once the handler returns, writes a reply or calls
+// Detach.
+type ResponseWriter struct {
+	conn       *serverConn
+	tag        string
+	cancel     context.CancelFunc
+	mu         sync.Mutex
+	finished   bool
+	started    chan struct{}
+	detachOnce sync.Once
+}
+
+// Detach lets the connection dispatch the next command while this one keeps
+// running, handlers that wait without replying, like listen, must call it
+func (w *ResponseWriter) Detach() {
+	w.detachOnce.Do(func() {
+		close(w.started)
+	})
+}
+
+// Write sends a reply sentence made of word and the attribute words
+func (w *ResponseWriter) Write(word string, words ...string) error {
+	w.Detach()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.finished {
+		return ErrFinished
+	}
+	if word == "!done" {
+		w.finished = true
+	}
+	return w.conn.write(w.sentence(word, words)...)
+}
+
+// Re sends a !re sentence
+func (w *ResponseWriter) Re(words ...string) error {
+	return w.Write("!re", words...)
+}
+
+// Done sends the final !done sentence
+func (w *ResponseWriter) Done(words ...string) error {
+	return w.Write("!done", words...)
+}
+
+// Empty sends !empty, the !done follows when the handler returns
+func (w *ResponseWriter) Empty() error {
+	return w.Write("!empty")
+}
+
+// Trap sends a !trap, the category is omitted when negative. The !done
+// follows when the handler returns.
+func (w *ResponseWriter) Trap(category int, message string) error {
+	var words []string
+	if category >= 0 {
+		words = append(words, "=category="+strconv.Itoa(category))
+	}
+	return w.Write("!trap", append(words, "=message="+message)...)
+}
+
+// Fatal sends an untagged !fatal and closes the connection
+func (w *ResponseWriter) Fatal(message string) error {
+	w.Detach()
+	w.mu.Lock()
+	w.finished = true
+	w.mu.Unlock()
+	err := w.conn.write("!fatal", "=message="+message)
+	w.conn.close()
+	return err
+}
+
+// interrupt answers a /cancel for the command
+func (w *ResponseWriter) interrupt() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.finished {
+		return
+	}
+	w.finished = true
+	w.cancel()
+	_ = w.conn.write(w.sentence("!trap", []string{"=category=2", "=message=interrupted"})...)
+	_ = w.conn.write(w.sentence("!done", nil)...)
+}
+
+func (w *ResponseWriter) sentence(word string, words []string) []string {
+	sentence := append([]string{word}, words...)
+	if w.tag != "" {
+		sentence = append(sentence, ".tag="+w.tag)
+	}
+	return sentence
+}
+
+// Step is one step of a scripted handler
+type Step func(ctx context.Context, w *ResponseWriter) error
+
+// Script returns a handler running the steps in order, it stops at the first
+// step that fails, e.g. because the command was cancelled
+func Script(steps ...Step) Handler {
+	return HandlerFunc(func(ctx context.Context, w *ResponseWriter, cmd *Command) {
+		for _, step := range steps {
+			if err := step(ctx, w); err != nil {
+				return
+			}
+		}
+	})
+}
+
+// Re sends a !re sentence with the attribute words
+func Re(words ...string) Step {
+	return func(ctx context.Context, w *ResponseWriter) error {
+		return w.Re(words...)
+	}
+}
+
+// Done sends the !done sentence with the attribute words, e.g. =ret=*1
+func Done(words ...string) Step {
+	return func(ctx context.Context, w *ResponseWriter) error {
+		return w.Done(words...)
+	}
+}
+
+// Empty sends !empty
+func Empty() Step {
+	return func(ctx context.Context, w *ResponseWriter) error {
+		return w.Empty()
+	}
+}
+
+// Trap sends a !trap with the category (omitted when negative) and message
+func Trap(category int, message string) Step {
+	return func(ctx context.Context, w *ResponseWriter) error {
+		return w.Trap(category, message)
+	}
+}
+
+// Fatal sends !fatal and closes the connection
+func Fatal(message string) Step {
+	return func(ctx context.Context, w *ResponseWriter) error {
+		return w.Fatal(message)
+	}
+}
+
+// Delay waits before the next step
+func Delay(d time.Duration) Step {
+	return func(ctx context.Context, w *ResponseWriter) error {
+		w.Detach()
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		}
+	}
+}
+
+// Block waits until the command is cancelled, like listen or follow prints
+func Block() Step {
+	return func(ctx context.Context, w *ResponseWriter) error {
+		w.Detach()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+}
@@ -3,6 +3,7 @@ package go_routeros
 import (
     "encoding/binary"
     "errors"
+    "strings"
 )
 
 // encodeLength encodes the word length in Mikrotik format
@@ -81,3 +82,126 @@ func (c *Client) decodeLength() (int, error) {
         return 0, errors.New("invalid length header")
     }
 }
+
+// Pair is a single key/value attribute word of a sentence
+type Pair struct {
+    Key   string
+    Value string
+}
+
+// Sentence is a reply sentence as sent by RouterOS. Word order and repeated
+// attribute names are preserved.
+type Sentence struct {
+    // Word reply word: !re, !done, !trap, !fatal or !empty
+    Word string
+    // List attribute words (=key=value) in the order they were received.
+    // RouterOS sends .id, .dead and .about as attribute words
+    // (=.id=*1), they are here and read with Get or Value.
+    List []Pair
+    // API API words sent without the = prefix (.tag=, .section=) in the order
+    // they were received
+    API []Pair
+}
+
+// parseSentence builds a Sentence from the raw words of a reply
+func parseSentence(words []string) Sentence {
+    var s Sentence
+    for _, word := range words {
+        switch {
+        case strings.HasPrefix(word, "!"):
+            s.Word = word
+        case strings.HasPrefix(word, "="):
+            key, value, _ := strings.Cut(word[1:], "=")
+            s.List = append(s.List, Pair{Key: key, Value: value})
+        case strings.HasPrefix(word, "."):
+            key, value, _ := strings.Cut(word, "=")
+            s.API = append(s.API, Pair{Key: key, Value: value})
+        }
+    }
+    return s
+}
+
+// Get returns the value of the first attribute with the given name
+func (s Sentence) Get(key string) (string, bool) {
+    for _, p := range s.List {
+        if p.Key == key {
+            return p.Value, true
+        }
+    }
+    return "", false
+}
+
+// Value returns the value of the first attribute with the given name or an empty string
+func (s Sentence) Value(key string) string {
+    v, _ := s.Get(key)
+    return v
+}
+
+// GetAll returns every value of the attributes with the given name, in order
+func (s Sentence) GetAll(key string) []string {
+    var values []string
+    for _, p := range s.List {
+        if p.Key == key {
+            values = append(values, p.Value)
+        }
+    }
+    return values
+}
+
+// Attr returns the value of an API word such as .tag or .section. It does not
+// cover .id, .dead or .about, which arrive as attribute words, see Value.
+func (s Sentence) Attr(key string) (string, bool) {
+    if !strings.HasPrefix(key, ".") {
+        key = "." + key
+    }
+    for _, p := range s.API {
+        if p.Key == key {
+            return p.Value, true
+        }
+    }
+    return "", false
+}
+
+// Tag returns the .tag API attribute
+func (s Sentence) Tag() string {
+    v, _ := s.Attr(".tag")
+    return v
+}
+
+// Each calls fn for every attribute word in order until fn returns false
+func (s Sentence) Each(fn func(key, value string) bool) {
+    for _, p := range s.List {
+        if !fn(p.Key, p.Value) {
+            return
+        }
+    }
+}
+
+// Map returns the attributes as a map. When a name is repeated the last value wins.
+func (s Sentence) Map() map[string]string {
+    m := make(map[string]string, len(s.List))
+    for _, p := range s.List {
+        m[p.Key] = p.Value
+    }
+    return m
+}
+
+// Words returns the sentence encoded back as API words
+func (s Sentence) Words() []string {
+    words := make([]string, 0, 1+len(s.List)+len(s.API))
+    if s.Word != "" {
+        words = append(words, s.Word)
+    }
+    for _, p := range s.List {
+        words = append(words, "="+p.Key+"="+p.Value)
+    }
+    for _, p := range s.API {
+        words = append(words, p.Key+"="+p.Value)
+    }
+    return words
+}
+
+// String returns the sentence words separated by spaces
+func (s Sentence) String() string {
+    return strings.Join(s.Words(), " ")
+}
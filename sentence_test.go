@@ -0,0 +1,39 @@
+package go_routeros
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSentence(t *testing.T) {
+	words := []string{"!re", "=.id=*1", "=name=ether1", "=comment=a=b", "=name=ether2", ".tag=3", ".section=1"}
+	s := parseSentence(words)
+
+	if s.Word != "!re" {
+		t.Errorf("expected word !re, got: %s", s.Word)
+	}
+	if v := s.Value(".id"); v != "*1" {
+		t.Errorf("expected .id *1, got: %s", v)
+	}
+	if v := s.Value("comment"); v != "a=b" {
+		t.Errorf("expected comment a=b, got: %s", v)
+	}
+	if v := s.GetAll("name"); !reflect.DeepEqual(v, []string{"ether1", "ether2"}) {
+		t.Errorf("expected both names, got: %v", v)
+	}
+	if s.Tag() != "3" {
+		t.Errorf("expected tag 3, got: %s", s.Tag())
+	}
+	if v, ok := s.Attr("section"); !ok || v != "1" {
+		t.Errorf("expected section 1, got: %s", v)
+	}
+	if _, ok := s.Attr(".id"); ok {
+		t.Errorf(".id is an attribute word, not an api word")
+	}
+	if _, ok := s.Get("tag"); ok {
+		t.Errorf("api attribute must not be mixed with the attribute words")
+	}
+	if !reflect.DeepEqual(s.Words(), words) {
+		t.Errorf("expected: %v, got: %v", words, s.Words())
+	}
+}
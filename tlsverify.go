@@ -0,0 +1,218 @@
+package go_routeros
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrFingerprintMismatch the key of the router is not the pinned or known one,
+// see FingerprintError
+var ErrFingerprintMismatch = errors.New("router key fingerprint mismatch")
+
+// FingerprintError is returned by Dial when the router presents an unexpected
+// key, the connection is closed before the login sends any credential
+type FingerprintError struct {
+	// Address the address dialled
+	Address string
+	// Expected the pinned or recorded fingerprints
+	Expected []string
+	// Got the fingerprint of the key presented by the router
+	Got string
+	// KnownHosts the file the fingerprint was recorded in, empty for pinned keys
+	KnownHosts string
+}
+
+func (e *FingerprintError) Error() string {
+	if e.KnownHosts != "" {
+		return fmt.Sprintf("the key of %s changed: %s was recorded in %s, the router presented %s; "+
+			"if the certificate was replaced on purpose, remove the line of %s from the file",
+			e.Address, strings.Join(e.Expected, ", "), e.KnownHosts, e.Got, e.Address)
+	}
+	return fmt.Sprintf("the key of %s is not pinned: expected %s, the router presented %s",
+		e.Address, strings.Join(e.Expected, " or "), e.Got)
+}
+
+func (e *FingerprintError) Unwrap() error {
+	return ErrFingerprintMismatch
+}
+
+// Fingerprint returns the SHA-256 of the SubjectPublicKeyInfo of cert in hex.
+// It stays the same when a certificate is renewed with the same key.
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeFingerprint accepts the colon separated and upper case forms, e.g.
+// of openssl
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(fingerprint), ":", ""))
+}
+
+// WithPinnedKey connects with API-SSL and accepts only a router whose key has
+// one of the fingerprints, see Fingerprint. The certificate chain and name are
+// not verified, so self-signed certificates work.
+func WithPinnedKey(fingerprints ...string) Option {
+	return func(cfg *config) {
+		for _, fingerprint := range fingerprints {
+			cfg.pinnedKeys = append(cfg.pinnedKeys, normalizeFingerprint(fingerprint))
+		}
+	}
+}
+
+// WithKnownHosts connects with API-SSL and trusts the key of a router on first
+// use: the fingerprint is recorded under the dialled address and a different
+// key is rejected afterwards with a FingerprintError
+func WithKnownHosts(hosts *KnownHosts) Option {
+	return func(cfg *config) {
+		cfg.knownHosts = hosts
+	}
+}
+
+// KnownHosts is a file of router key fingerprints, one "address fingerprint"
+// per line, lines starting with # are comments. It is safe for concurrent use.
+type KnownHosts struct {
+	path string
+
+	mu    sync.Mutex
+	hosts map[string]string
+}
+
+// NewKnownHosts loads the file at path, a missing file is created on the
+// first record
+func NewKnownHosts(path string) (*KnownHosts, error) {
+	k := &KnownHosts{path: path, hosts: make(map[string]string)}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return k, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected an address and a fingerprint", path, n)
+		}
+		k.hosts[fields[0]] = normalizeFingerprint(fields[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Lookup returns the fingerprint recorded for address
+func (k *KnownHosts) Lookup(address string) (string, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	fingerprint, ok := k.hosts[address]
+	return fingerprint, ok
+}
+
+// Verify checks the key of cert against the one recorded for address, the key
+// of an unknown address is recorded
+func (k *KnownHosts) Verify(address string, cert *x509.Certificate) error {
+	got := Fingerprint(cert)
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if expected, ok := k.hosts[address]; ok {
+		if expected != got {
+			return &FingerprintError{Address: address, Expected: []string{expected}, Got: got, KnownHosts: k.path}
+		}
+		return nil
+	}
+	file, err := os.OpenFile(k.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("could not record the key of %s: %w", address, err)
+	}
+	if _, err = fmt.Fprintf(file, "%s %s\n", address, got); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("could not record the key of %s: %w", address, err)
+	}
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("could not record the key of %s: %w", address, err)
+	}
+	k.hosts[address] = got
+	return nil
+}
+
+// Remove forgets the key of address, e.g. after its certificate was replaced
+func (k *KnownHosts) Remove(address string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.hosts[address]; !ok {
+		return nil
+	}
+	delete(k.hosts, address)
+	var b strings.Builder
+	b.WriteString("# router key fingerprints, see go_routeros.KnownHosts\n")
+	hosts := make([]string, 0, len(k.hosts))
+	for host := range k.hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		fmt.Fprintf(&b, "%s %s\n", host, k.hosts[host])
+	}
+	return os.WriteFile(k.path, []byte(b.String()), 0o600)
+}
+
+// verifiedTLSConfig returns the TLS config of Dial, with the key of the router
+// checked against the pinned keys or the known hosts
+func verifiedTLSConfig(cfg *config, addr string) *tls.Config {
+	tlsConfig := cfg.tlsConfig
+	if len(cfg.pinnedKeys) == 0 && cfg.knownHosts == nil {
+		return tlsConfig
+	}
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	} else {
+		tlsConfig = tlsConfig.Clone()
+	}
+	// the pin replaces the verification of the chain, which fails for the
+	// self-signed certificates of most routers
+	tlsConfig.InsecureSkipVerify = true
+	verify := tlsConfig.VerifyConnection
+	tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return errors.New("the router presented no certificate")
+		}
+		leaf := state.PeerCertificates[0]
+		if len(cfg.pinnedKeys) > 0 {
+			got := Fingerprint(leaf)
+			pinned := false
+			for _, fingerprint := range cfg.pinnedKeys {
+				pinned = pinned || fingerprint == got
+			}
+			if !pinned {
+				return &FingerprintError{Address: addr, Expected: cfg.pinnedKeys, Got: got}
+			}
+		}
+		if cfg.knownHosts != nil {
+			if err := cfg.knownHosts.Verify(addr, leaf); err != nil {
+				return err
+			}
+		}
+		if verify != nil {
+			return verify(state)
+		}
+		return nil
+	}
+	return tlsConfig
+}
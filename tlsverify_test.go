@@ -0,0 +1,188 @@
+package go_routeros
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leandrose/go-routeros/routerostest"
+)
+
+// selfSignedCertificate generates the kind of certificate a router creates for api-ssl
+func selfSignedCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "router"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("certificate: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func listenTLS(t *testing.T, cert tls.Certificate) string {
+	t.Helper()
+	server := routerostest.NewServer()
+	t.Cleanup(server.Close)
+	addr, err := server.ListenTLS(&tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	return addr
+}
+
+func dialVerified(addr string, opts ...Option) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client, err := Dial(ctx, addr, opts...)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.Login("admin", "")
+}
+
+func TestPinnedKey(t *testing.T) {
+	cert := selfSignedCertificate(t)
+	other := selfSignedCertificate(t)
+	addr := listenTLS(t, cert)
+	fingerprint := Fingerprint(cert.Leaf)
+
+	tests := []struct {
+		name  string
+		pins  []string
+		errIs error
+	}{
+		{name: "Chave fixada", pins: []string{fingerprint}},
+		{name: "Formato com dois pontos", pins: []string{colonFingerprint(strings.ToUpper(fingerprint))}},
+		{name: "Uma das chaves", pins: []string{Fingerprint(other.Leaf), fingerprint}},
+		{name: "Chave diferente", pins: []string{Fingerprint(other.Leaf)}, errIs: ErrFingerprintMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := dialVerified(addr, WithPinnedKey(tt.pins...))
+			if tt.errIs == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var mismatch *FingerprintError
+			if !errors.Is(err, tt.errIs) || !errors.As(err, &mismatch) {
+				t.Fatalf("expected %v, got: %v", tt.errIs, err)
+			}
+			if mismatch.Got != fingerprint {
+				t.Errorf("expected the fingerprint %s, got: %s", fingerprint, mismatch.Got)
+			}
+		})
+	}
+}
+
+func TestKnownHosts(t *testing.T) {
+	cert := selfSignedCertificate(t)
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	addr := listenTLS(t, cert)
+
+	t.Run("Primeira conexão grava a chave", func(t *testing.T) {
+		hosts, err := NewKnownHosts(path)
+		if err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		if err = dialVerified(addr, WithKnownHosts(hosts)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, _ := hosts.Lookup(addr); got != Fingerprint(cert.Leaf) {
+			t.Errorf("expected the fingerprint to be recorded, got: %q", got)
+		}
+	})
+
+	t.Run("Chave conhecida", func(t *testing.T) {
+		hosts, err := NewKnownHosts(path)
+		if err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		if err = dialVerified(addr, WithKnownHosts(hosts)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Chave alterada", func(t *testing.T) {
+		// another router answers on the recorded address
+		changed := selfSignedCertificate(t)
+		data, _ := os.ReadFile(path)
+		data = []byte(strings.ReplaceAll(string(data), Fingerprint(cert.Leaf), Fingerprint(changed.Leaf)))
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		hosts, err := NewKnownHosts(path)
+		if err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		err = dialVerified(addr, WithKnownHosts(hosts))
+		var mismatch *FingerprintError
+		if !errors.As(err, &mismatch) {
+			t.Fatalf("expected a FingerprintError, got: %v", err)
+		}
+		if mismatch.KnownHosts != path || !strings.Contains(err.Error(), "changed") {
+			t.Errorf("expected the file in the error, got: %v", err)
+		}
+	})
+
+	t.Run("Remover chave", func(t *testing.T) {
+		hosts, err := NewKnownHosts(path)
+		if err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		if err = hosts.Remove(addr); err != nil {
+			t.Fatalf("remove: %v", err)
+		}
+		if err = dialVerified(addr, WithKnownHosts(hosts)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		reloaded, _ := NewKnownHosts(path)
+		if got, _ := reloaded.Lookup(addr); got != Fingerprint(cert.Leaf) {
+			t.Errorf("expected the new fingerprint, got: %q", got)
+		}
+	})
+
+	t.Run("Arquivo inválido", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), "known_hosts")
+		if err := os.WriteFile(invalid, []byte("# comment\n192.168.88.1:8729\n"), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if _, err := NewKnownHosts(invalid); err == nil || !strings.Contains(err.Error(), ":2:") {
+			t.Errorf("expected the line in the error, got: %v", err)
+		}
+	})
+}
+
+func colonFingerprint(fingerprint string) string {
+	var parts []string
+	for i := 0; i+2 <= len(fingerprint); i += 2 {
+		parts = append(parts, fingerprint[i:i+2])
+	}
+	return strings.Join(parts, ":")
+}
@@ -0,0 +1,303 @@
+package go_routeros
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leandrose/go-routeros/rostypes"
+)
+
+// ID is the .id of an item, e.g. *1A
+type ID string
+
+// Number returns the number of the .id
+func (id ID) Number() (uint64, error) {
+	if !strings.HasPrefix(string(id), "*") {
+		return 0, fmt.Errorf("invalid .id %q", string(id))
+	}
+	return strconv.ParseUint(string(id[1:]), 16, 64)
+}
+
+// FieldError reports an attribute that could not be converted to its field
+type FieldError struct {
+	// Attribute the RouterOS attribute name
+	Attribute string
+	// Field the Go struct field name
+	Field string
+	// Value the attribute value
+	Value string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("routeros: attribute %s (field %s): cannot use %q: %v", e.Attribute, e.Field, e.Value, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+	ipType              = reflect.TypeOf(net.IP(nil))
+	addrType            = reflect.TypeOf(netip.Addr{})
+	prefixType          = reflect.TypeOf(netip.Prefix{})
+)
+
+// structField a field tagged with routeros:"name"
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	readOnly  bool
+}
+
+// structFields returns the tagged fields of the struct type t. Fields without
+// the tag or tagged with "-" are ignored. The options after the name are
+// omitempty and readonly.
+func structFields(t reflect.Type) ([]structField, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("routeros: %s is not a struct", t)
+	}
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("routeros")
+		if !ok || tag == "-" || !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			return nil, fmt.Errorf("routeros: field %s has an empty attribute name", f.Name)
+		}
+		fields = append(fields, structField{
+			name:      name,
+			index:     f.Index,
+			omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+			readOnly:  strings.Contains(","+opts+",", ",readonly,"),
+		})
+	}
+	return fields, nil
+}
+
+// Proplist returns the attribute names of the struct v for =.proplist=
+func Proplist(v interface{}) (string, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return "", errors.New("routeros: Proplist of nil")
+	}
+	fields, err := structFields(t)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+	}
+	return strings.Join(names, ","), nil
+}
+
+// Unmarshal stores the attributes of the sentence in the struct pointed to by
+// v, using the routeros:"name" tag of each field. Attributes missing from the
+// sentence leave their field untouched. Besides strings, numbers and
+// encoding.TextUnmarshaler it converts yes/no/true/false booleans, durations
+// such as 1w2d03:04:05, byte sizes such as 1.5KiB, net.IP, netip.Addr,
+// netip.Prefix and comma separated lists into slices.
+func Unmarshal(s Sentence, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("routeros: Unmarshal requires a non-nil pointer")
+	}
+	rv = rv.Elem()
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		value, ok := s.Get(f.name)
+		if !ok {
+			continue
+		}
+		field := rv.FieldByIndex(f.index)
+		if err := setValue(field, value); err != nil {
+			return &FieldError{
+				Attribute: f.name,
+				Field:     rv.Type().FieldByIndex(f.index).Name,
+				Value:     value,
+				Err:       err,
+			}
+		}
+	}
+	return nil
+}
+
+// Print runs a print on the menu, e.g. /ip/address, and unmarshals every item
+// into T. Only the attributes of T are fetched with =.proplist= unless args
+// already has one; args may carry query words.
+func Print[T any](ctx context.Context, client *Client, menu string, args ...string) ([]T, error) {
+	var zero T
+	hasProplist := false
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "=.proplist=") {
+			hasProplist = true
+		}
+	}
+	if !hasProplist {
+		proplist, err := Proplist(zero)
+		if err != nil {
+			return nil, err
+		}
+		args = append([]string{"=.proplist=" + proplist}, args...)
+	}
+	if !strings.HasSuffix(menu, "/print") {
+		menu += "/print"
+	}
+
+	reply, err := client.Run(ctx, menu, args...)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]T, len(reply.Re))
+	for i, s := range reply.Re {
+		if err := Unmarshal(s, &items[i]); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+func setValue(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return setValue(field.Elem(), value)
+	}
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch field.Type() {
+	case durationType:
+		d, err := rostypes.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	case ipType:
+		if value == "" {
+			field.Set(reflect.Zero(ipType))
+			return nil
+		}
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return errors.New("invalid IP address")
+		}
+		field.Set(reflect.ValueOf(ip))
+		return nil
+	case addrType:
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(addr))
+		return nil
+	case prefixType:
+		prefix, err := parsePrefix(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(prefix))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := rostypes.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := parseInt(value)
+		if err != nil {
+			return err
+		}
+		if field.OverflowInt(n) {
+			return errors.New("value out of range")
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := parseInt(value)
+		if err != nil {
+			return err
+		}
+		if n < 0 || field.OverflowUint(uint64(n)) {
+			return errors.New("value out of range")
+		}
+		field.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		field.Set(reflect.MakeSlice(field.Type(), 0, 0))
+		if value == "" {
+			return nil
+		}
+		for _, part := range strings.Split(value, ",") {
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if err := setValue(elem, part); err != nil {
+				return err
+			}
+			field.Set(reflect.Append(field, elem))
+		}
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// parsePrefix accepts an address with or without the prefix length
+func parsePrefix(value string) (netip.Prefix, error) {
+	if !strings.Contains(value, "/") {
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	return netip.ParsePrefix(value)
+}
+
+// parseInt parses an integer, accepting sizes such as 1.5KiB or rates such as 10M
+func parseInt(value string) (int64, error) {
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return n, nil
+	}
+	if size, err := rostypes.ParseSize(value); err == nil && size <= math.MaxInt64 {
+		return int64(size), nil
+	}
+	if rate, err := rostypes.ParseRate(value); err == nil && rate <= math.MaxInt64 {
+		return int64(rate), nil
+	}
+	return 0, errors.New("invalid integer")
+}
@@ -0,0 +1,108 @@
+package go_routeros
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/leandrose/go-routeros/routerossim"
+)
+
+type testInterface struct {
+	ID       ID            `routeros:".id"`
+	Name     string        `routeros:"name"`
+	MTU      int           `routeros:"mtu"`
+	Running  bool          `routeros:"running"`
+	Disabled *bool         `routeros:"disabled"`
+	Uptime   time.Duration `routeros:"uptime"`
+	RxBytes  uint64        `routeros:"rx-byte"`
+	Gateway  net.IP        `routeros:"gateway"`
+	Address  netip.Prefix  `routeros:"address"`
+	Topics   []string      `routeros:"topics"`
+	Vlans    []int         `routeros:"vlan-ids"`
+	Load     float64       `routeros:"cpu-load"`
+	Ignored  string        `routeros:"-"`
+	Untagged string
+}
+
+func TestUnmarshal(t *testing.T) {
+	s := parseSentence([]string{
+		"!re", "=.id=*1A", "=name=ether1", "=mtu=1500", "=running=true", "=disabled=no",
+		"=uptime=1w2d03:04:05.5", "=rx-byte=1.5KiB", "=gateway=10.0.0.1", "=address=10.0.0.2/24",
+		"=topics=system,info", "=vlan-ids=10,20", "=cpu-load=12%", "=unknown=x",
+	})
+	var got testInterface
+	if err := Unmarshal(s, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	disabled := false
+	expected := testInterface{
+		ID:       "*1A",
+		Name:     "ether1",
+		MTU:      1500,
+		Running:  true,
+		Disabled: &disabled,
+		Uptime:   9*24*time.Hour + 3*time.Hour + 4*time.Minute + 5500*time.Millisecond,
+		RxBytes:  1536,
+		Gateway:  net.ParseIP("10.0.0.1"),
+		Address:  netip.MustParsePrefix("10.0.0.2/24"),
+		Topics:   []string{"system", "info"},
+		Vlans:    []int{10, 20},
+		Load:     12,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected: %+v, got: %+v", expected, got)
+	}
+	if n, err := got.ID.Number(); err != nil || n != 0x1A {
+		t.Errorf("expected .id number 26, got: %d %v", n, err)
+	}
+
+	proplist, err := Proplist(testInterface{})
+	if err != nil {
+		t.Fatalf("proplist: %v", err)
+	}
+	if proplist != ".id,name,mtu,running,disabled,uptime,rx-byte,gateway,address,topics,vlan-ids,cpu-load" {
+		t.Errorf("unexpected proplist: %s", proplist)
+	}
+}
+
+func TestUnmarshalFieldError(t *testing.T) {
+	var got testInterface
+	err := Unmarshal(parseSentence([]string{"!re", "=mtu=big"}), &got)
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Attribute != "mtu" || fieldErr.Field != "MTU" {
+		t.Fatalf("expected a field error for mtu, got: %v", err)
+	}
+}
+
+func TestPrint(t *testing.T) {
+	sim := routerossim.New()
+	for _, name := range []string{"ether1", "ether2"} {
+		if _, err := sim.Add("/interface", map[string]string{"name": name, "mtu": "1500", "type": "ether"}); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	client := NewClient(sim.Server().Pipe())
+	if err := client.Login("admin", ""); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	defer client.Close()
+
+	type iface struct {
+		ID   ID     `routeros:".id"`
+		Name string `routeros:"name"`
+		MTU  int    `routeros:"mtu"`
+	}
+	items, err := Print[iface](context.Background(), client, "/interface", Where("name").Eq("ether2").Words()...)
+	if err != nil {
+		t.Fatalf("print: %v", err)
+	}
+	if len(items) != 1 || items[0] != (iface{ID: "*2", Name: "ether2", MTU: 1500}) {
+		t.Errorf("unexpected items: %+v", items)
+	}
+}
@@ -0,0 +1,313 @@
+package go_routeros
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// watchBuffer pending listen replies kept by Watch, a consumer falling further
+// behind makes the watcher list the menu again
+const watchBuffer = 1024
+
+// EventType kind of change reported by a Watcher
+type EventType int
+
+const (
+	// Added an item appeared in the menu or started to match the query
+	Added EventType = iota
+	// Updated attributes of an item changed
+	Updated
+	// Removed an item was removed or stopped matching the query
+	Removed
+)
+
+func (t EventType) String() string {
+	switch t {
+	case Added:
+		return "added"
+	case Updated:
+		return "updated"
+	case Removed:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// Event is a change of an item of the watched menu
+type Event struct {
+	Type EventType
+	// ID the .id of the item
+	ID string
+	// Item the current attributes, the last known ones for Removed
+	Item Sentence
+	// Old the previous attributes for Updated
+	Old Sentence
+	// Changed the attributes added, changed or removed by an Updated, sorted
+	Changed []string
+}
+
+// Watcher keeps a local copy of the items of a menu up to date from /listen
+// and reports the changes as events
+type Watcher struct {
+	client *Client
+	menu   string
+	query  Query
+	listen *Request
+	events chan Event
+
+	mu     sync.Mutex
+	items  map[string]Sentence
+	err    error
+	synced chan struct{}
+	done   chan struct{}
+
+	syncOnce sync.Once
+}
+
+// Watch lists the items of menu matching query with print and streams the
+// changes received by listen. Every listed item is reported as Added first.
+// After a reconnect or when listen replies were dropped because the consumer
+// fell behind, the menu is listed again and the differences are reported.
+//
+// The query is evaluated locally against the listen replies, so an item that
+// stops matching it is reported as Removed. The watcher stops when ctx is done
+// or listen fails, Err returns the cause.
+func (c *Client) Watch(ctx context.Context, menu string, query Query) (*Watcher, error) {
+	// listen starts first so no change is lost while the print runs
+	listen, err := c.SendCommandWithOptions(ctx, CommandOptions{
+		Delivery:    DropOnOverflow(watchBuffer),
+		Resubscribe: true,
+	}, menu+"/listen")
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{
+		client: c,
+		menu:   menu,
+		query:  query,
+		listen: listen,
+		events: make(chan Event),
+		items:  make(map[string]Sentence),
+		synced: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go w.run(ctx)
+	return w, nil
+}
+
+// Events returns the channel of changes, it is closed when the watcher stops
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Synced returns a channel that is closed once the events of the first
+// listing were received
+func (w *Watcher) Synced() <-chan struct{} {
+	return w.synced
+}
+
+// Done returns a channel that is closed when the watcher stops
+func (w *Watcher) Done() <-chan struct{} {
+	return w.done
+}
+
+// Err returns why the watcher stopped, or nil while it is running
+func (w *Watcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Items returns the items known to the watcher
+func (w *Watcher) Items() []Sentence {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	items := make([]Sentence, 0, len(w.items))
+	for _, item := range w.items {
+		items = append(items, item)
+	}
+	sortItems(items)
+	return items
+}
+
+// Get returns the item with the .id
+func (w *Watcher) Get(id string) (Sentence, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	item, ok := w.items[id]
+	return item, ok
+}
+
+func (w *Watcher) run(ctx context.Context) {
+	err := w.watch(ctx)
+	_ = w.listen.Cancel()
+	w.mu.Lock()
+	w.err = err
+	w.mu.Unlock()
+	close(w.events)
+	close(w.done)
+}
+
+func (w *Watcher) watch(ctx context.Context) error {
+	// replies dropped before the first listing are covered by it
+	dropped := w.listen.Dropped()
+	if err := w.resync(ctx); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case response, ok := <-w.listen.Responses():
+			if !ok {
+				if err := w.listen.Err(); err != nil {
+					return err
+				}
+				return ErrClosed
+			}
+			switch response.Type {
+			case "!re":
+				if err := w.apply(ctx, response.Sentence); err != nil {
+					return err
+				}
+			case "!trap", "!fatal":
+				return response.Err
+			case "!done", "!empty":
+				return ErrClosed
+			}
+			if n := w.listen.Dropped(); response.Type == TypeResynced || n != dropped {
+				dropped = n
+				if err := w.resync(ctx); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// resync lists the menu again and reports the differences with the local copy.
+// A listing that fails because the connection was lost is retried when the
+// listen is resubscribed.
+func (w *Watcher) resync(ctx context.Context) error {
+	reply, err := w.client.Run(ctx, w.menu+"/print", w.query.Words()...)
+	if err != nil {
+		var rosErr *RouterOSError
+		if ctx.Err() == nil && (errors.Is(err, ErrReconnecting) || errors.As(err, &rosErr) && rosErr.Fatal) {
+			return nil
+		}
+		return err
+	}
+
+	current := make(map[string]Sentence, len(reply.Re))
+	for _, item := range reply.Re {
+		if id := item.Value(".id"); id != "" {
+			current[id] = item
+		}
+	}
+
+	w.mu.Lock()
+	var removed []Event
+	for id, old := range w.items {
+		if _, ok := current[id]; !ok {
+			delete(w.items, id)
+			removed = append(removed, Event{Type: Removed, ID: id, Item: old})
+		}
+	}
+	w.mu.Unlock()
+	sort.Slice(removed, func(i, j int) bool {
+		return lessID(removed[i].ID, removed[j].ID)
+	})
+	for _, e := range removed {
+		if err := w.emit(ctx, e); err != nil {
+			return err
+		}
+	}
+	for _, item := range reply.Re {
+		if err := w.update(ctx, item); err != nil {
+			return err
+		}
+	}
+	w.syncOnce.Do(func() {
+		close(w.synced)
+	})
+	return nil
+}
+
+// apply handles a listen reply, .dead=yes is sent for removed items
+func (w *Watcher) apply(ctx context.Context, item Sentence) error {
+	id := item.Value(".id")
+	if id == "" {
+		return nil
+	}
+	if item.Value(".dead") == "yes" || !w.query.Match(item) {
+		w.mu.Lock()
+		old, ok := w.items[id]
+		delete(w.items, id)
+		w.mu.Unlock()
+		if !ok {
+			return nil
+		}
+		return w.emit(ctx, Event{Type: Removed, ID: id, Item: old})
+	}
+	return w.update(ctx, item)
+}
+
+// update stores the item and reports it when it is new or changed
+func (w *Watcher) update(ctx context.Context, item Sentence) error {
+	id := item.Value(".id")
+	if id == "" {
+		return nil
+	}
+	w.mu.Lock()
+	old, ok := w.items[id]
+	w.items[id] = item
+	w.mu.Unlock()
+
+	if !ok {
+		return w.emit(ctx, Event{Type: Added, ID: id, Item: item})
+	}
+	changed := changedAttributes(old, item)
+	if len(changed) == 0 {
+		return nil
+	}
+	return w.emit(ctx, Event{Type: Updated, ID: id, Item: item, Old: old, Changed: changed})
+}
+
+func (w *Watcher) emit(ctx context.Context, e Event) error {
+	select {
+	case w.events <- e:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// changedAttributes returns the attributes whose value differs, sorted
+func changedAttributes(old, new Sentence) []string {
+	before, after := old.Map(), new.Map()
+	var changed []string
+	for k, v := range after {
+		if prev, ok := before[k]; !ok || prev != v {
+			changed = append(changed, k)
+		}
+	}
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// lessID orders .id values by their number, e.g. *2 before *A
+func lessID(a, b string) bool {
+	x, errA := ID(a).Number()
+	y, errB := ID(b).Number()
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return x < y
+}
@@ -0,0 +1,152 @@
+package go_routeros
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/leandrose/go-routeros/routerossim"
+)
+
+// nextEvent waits for the next event of the watcher
+func nextEvent(t *testing.T, w *Watcher) Event {
+	t.Helper()
+	select {
+	case e, ok := <-w.Events():
+		if !ok {
+			t.Fatalf("watcher stopped: %v", w.Err())
+		}
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout waiting for an event")
+	}
+	return Event{}
+}
+
+func TestWatch(t *testing.T) {
+	sim := routerossim.New()
+	for _, values := range []map[string]string{
+		{"name": "alice", "profile": "vip"},
+		{"name": "bob", "profile": "default"},
+	} {
+		if _, err := sim.Add("/ppp/secret", values); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	client := NewClient(sim.Server().Pipe())
+	if err := client.Login("admin", ""); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := client.Watch(ctx, "/ppp/secret", Where("profile").Eq("vip"))
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	if e := nextEvent(t, w); e.Type != Added || e.Item.Value("name") != "alice" {
+		t.Fatalf("expected alice to be added, got: %s %v", e.Type, e.Item)
+	}
+
+	reply, err := client.Run(ctx, "/ppp/secret/add", "=name=carol", "=profile=vip")
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	carol := reply.Ret()
+	if e := nextEvent(t, w); e.Type != Added || e.ID != carol {
+		t.Fatalf("expected carol to be added, got: %s %v", e.Type, e.Item)
+	}
+
+	alice := w.Items()[0].Value(".id")
+	if _, err := client.Run(ctx, "/ppp/secret/set", "=.id="+alice, "=comment=boss"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	e := nextEvent(t, w)
+	if e.Type != Updated || e.ID != alice || !reflect.DeepEqual(e.Changed, []string{"comment"}) || e.Old.Value("comment") != "" {
+		t.Fatalf("expected the comment of alice to change, got: %s %v %v", e.Type, e.Changed, e.Item)
+	}
+
+	// alice no longer matches the query
+	if _, err := client.Run(ctx, "/ppp/secret/set", "=.id="+alice, "=profile=default"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if e := nextEvent(t, w); e.Type != Removed || e.ID != alice {
+		t.Fatalf("expected alice to be removed, got: %s %v", e.Type, e.Item)
+	}
+	if _, err := client.Run(ctx, "/ppp/secret/remove", "=.id="+carol); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if e := nextEvent(t, w); e.Type != Removed || e.ID != carol || e.Item.Value("name") != "carol" {
+		t.Fatalf("expected carol to be removed, got: %s %v", e.Type, e.Item)
+	}
+	if items := w.Items(); len(items) != 0 {
+		t.Errorf("expected no items, got: %v", items)
+	}
+
+	cancel()
+	select {
+	case <-w.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatalf("watcher did not stop")
+	}
+	if _, ok := <-w.Events(); ok {
+		t.Errorf("expected the events channel to be closed")
+	}
+}
+
+func TestWatchResyncAfterReconnect(t *testing.T) {
+	sim := routerossim.New()
+	alice, err := sim.Add("/ppp/secret", map[string]string{"name": "alice"})
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	server := sim.Server()
+	addr, err := server.Listen()
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer server.Close()
+
+	client, err := DialContext(context.Background(), addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+	client.EnableReconnect(Backoff{Min: 200 * time.Millisecond, Max: time.Second})
+	if err := client.Login("admin", ""); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := client.Watch(ctx, "/ppp/secret", Query{})
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	if e := nextEvent(t, w); e.Type != Added || e.ID != alice {
+		t.Fatalf("expected alice to be added, got: %s %v", e.Type, e.Item)
+	}
+
+	// the changes happen while the watcher is disconnected
+	server.CloseConnections()
+	other := NewClient(sim.Server().Pipe())
+	if err := other.Login("admin", ""); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	defer other.Close()
+	if _, err := other.Run(ctx, "/ppp/secret/remove", "=.id="+alice); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, err := other.Run(ctx, "/ppp/secret/add", "=name=bob"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	if e := nextEvent(t, w); e.Type != Removed || e.ID != alice {
+		t.Fatalf("expected alice to be removed, got: %s %v", e.Type, e.Item)
+	}
+	if e := nextEvent(t, w); e.Type != Added || e.Item.Value("name") != "bob" {
+		t.Fatalf("expected bob to be added, got: %s %v", e.Type, e.Item)
+	}
+}
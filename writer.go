@@ -1,27 +1,45 @@
 package go_routeros
 
-import "fmt"
+import (
+	"bytes"
+	"sync"
+	"time"
+)
 
+var sentencePool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// writeSentence encodes the sentence into a single buffer and writes it to the
+// connection at once, so sentences of concurrent commands never interleave
 func (c *Client) writeSentence(words []string) error {
-    for _, word := range words {
-        if c.debug {
-            fmt.Printf("DEBUG WRITER: %s\n", word)
-        }
-        if err := c.writeWord(word); err != nil {
-            return err
-        }
-    }
-    return c.writeWord("") // fim da sentença
+	c.logWords("write sentence", words)
+
+	buf := sentencePool.Get().(*bytes.Buffer)
+	defer sentencePool.Put(buf)
+	buf.Reset()
+	encodeSentence(buf, words)
+
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	if c.writeTimeout > 0 {
+		if d, ok := c.conn.(interface{ SetWriteDeadline(time.Time) error }); ok {
+			_ = d.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+			defer d.SetWriteDeadline(time.Time{})
+		}
+	}
+	_, err := c.conn.Write(buf.Bytes())
+	return err
 }
 
-// writeWord writes a word (with length prefix) to the connection
-func (c *Client) writeWord(word string) error {
-    length := encodeLength(len(word))
-    if _, err := c.conn.Write(length); err != nil {
-        return err
-    }
-    if _, err := c.conn.Write([]byte(word)); err != nil {
-        return err
-    }
-    return nil
+// encodeSentence writes each word with its length prefix followed by the
+// empty word that ends the sentence
+func encodeSentence(buf *bytes.Buffer, words []string) {
+	for _, word := range words {
+		buf.Write(encodeLength(len(word)))
+		buf.WriteString(word)
+	}
+	buf.WriteByte(0)
 }
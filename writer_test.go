@@ -0,0 +1,54 @@
+package go_routeros
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWriteSentenceConcurrent(t *testing.T) {
+	local, remote := net.Pipe()
+	defer local.Close()
+	defer remote.Close()
+
+	writer := &Client{conn: local}
+	reader := &Client{reader: bufio.NewReader(remote)}
+
+	const writers, sentences = 8, 50
+	wg := sync.WaitGroup{}
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < sentences; i++ {
+				words := []string{
+					"/ip/address/print",
+					fmt.Sprintf("=comment=%s", strings.Repeat("x", 200+w)),
+					fmt.Sprintf(".tag=%d", w),
+				}
+				if err := writer.writeSentence(words); err != nil {
+					t.Errorf("write: %v", err)
+					return
+				}
+			}
+		}(w)
+	}
+
+	for i := 0; i < writers*sentences; i++ {
+		sentence, err := reader.readSentence()
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		var w int
+		if _, err := fmt.Sscan(sentence.Tag(), &w); err != nil {
+			t.Fatalf("invalid tag %q: %v", sentence.Tag(), err)
+		}
+		if comment := sentence.Value("comment"); len(comment) != 200+w {
+			t.Fatalf("sentence interleaved: tag=%d comment length=%d", w, len(comment))
+		}
+	}
+	wg.Wait()
+}